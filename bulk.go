@@ -0,0 +1,293 @@
+package goshopify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// BulkOperationService is an interface for running bulk queries through the
+// GraphQL Admin API.
+// See: https://shopify.dev/docs/api/usage/bulk-operations/queries
+type BulkOperationService interface {
+	Run(ctx context.Context, query string, options *BulkOperationOptions, fn func(*BulkObject) error) (*BulkOperation, error)
+	Submit(ctx context.Context, query string) (*BulkOperation, error)
+	Current(ctx context.Context) (*BulkOperation, error)
+	Wait(ctx context.Context, operationID string, options *BulkOperationOptions) (*BulkOperation, error)
+	Stream(ctx context.Context, operation *BulkOperation, fn func(*BulkObject) error) error
+}
+
+// BulkOperationServiceOp handles communication with the bulk operation related
+// queries and mutations of the GraphQL Admin API.
+type BulkOperationServiceOp struct {
+	client *Client
+}
+
+// Statuses of a bulk operation.
+const (
+	BulkOperationCreated   = "CREATED"
+	BulkOperationRunning   = "RUNNING"
+	BulkOperationCompleted = "COMPLETED"
+	BulkOperationCanceling = "CANCELING"
+	BulkOperationCanceled  = "CANCELED"
+	BulkOperationFailed    = "FAILED"
+	BulkOperationExpired   = "EXPIRED"
+)
+
+// BulkOperation represents a Shopify bulk operation.
+type BulkOperation struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ErrorCode      string `json:"errorCode"`
+	ObjectCount    string `json:"objectCount"`
+	URL            string `json:"url"`
+	PartialDataURL string `json:"partialDataUrl"`
+}
+
+// BulkOperationError is returned when a bulk operation did not complete.
+type BulkOperationError struct {
+	Operation BulkOperation
+}
+
+func (e BulkOperationError) Error() string {
+	if e.Operation.ErrorCode != "" {
+		return fmt.Sprintf("bulk operation %s %s: %s", e.Operation.ID, e.Operation.Status, e.Operation.ErrorCode)
+	}
+	return fmt.Sprintf("bulk operation %s %s", e.Operation.ID, e.Operation.Status)
+}
+
+// BulkOperationOptions configures how a bulk operation is polled until it is
+// finished. The interval starts at MinPollInterval and doubles after every
+// poll up to MaxPollInterval. Zero or negative intervals are replaced by the
+// defaults of 1 and 30 seconds.
+type BulkOperationOptions struct {
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
+}
+
+var defaultBulkOperationOptions = BulkOperationOptions{
+	MinPollInterval: time.Second,
+	MaxPollInterval: 30 * time.Second,
+}
+
+// Returns the options with the defaults filled in, one field at a time, so
+// that polling never spins without a delay.
+func (o *BulkOperationOptions) withDefaults() BulkOperationOptions {
+	opts := defaultBulkOperationOptions
+	if o == nil {
+		return opts
+	}
+
+	if o.MinPollInterval > 0 {
+		opts.MinPollInterval = o.MinPollInterval
+	}
+	if o.MaxPollInterval > 0 {
+		opts.MaxPollInterval = o.MaxPollInterval
+	}
+	if opts.MaxPollInterval < opts.MinPollInterval {
+		opts.MaxPollInterval = opts.MinPollInterval
+	}
+	return opts
+}
+
+// BulkObject is a single object of the JSONL result of a bulk query. Nested
+// connections are flattened into separate objects by Shopify, which refer to
+// the object they belong to by its ID.
+type BulkObject struct {
+	// The ID of the object, if it was selected in the query.
+	ID string
+
+	// The ID of the parent object for objects of nested connections.
+	ParentID string
+
+	// The parent object, if it was part of the result.
+	Parent *BulkObject
+
+	// The JSON of the object, including the __parentId field.
+	Raw json.RawMessage
+}
+
+// Decode decodes the JSON of the object into v.
+func (o *BulkObject) Decode(v interface{}) error {
+	return json.Unmarshal(o.Raw, v)
+}
+
+const bulkOperationFields = `id status errorCode objectCount url partialDataUrl`
+
+const bulkOperationRunQueryMutation = `mutation bulkOperationRunQuery($query: String!) {
+  bulkOperationRunQuery(query: $query) {
+    bulkOperation { ` + bulkOperationFields + ` }
+    userErrors { field message }
+  }
+}`
+
+const currentBulkOperationQuery = `query { currentBulkOperation { ` + bulkOperationFields + ` } }`
+
+// Run submits a bulk query, waits for it to finish and streams the objects of
+// the result to fn. A BulkOperationError is returned if the operation did not
+// complete.
+func (s *BulkOperationServiceOp) Run(ctx context.Context, query string, options *BulkOperationOptions, fn func(*BulkObject) error) (*BulkOperation, error) {
+	operation, err := s.Submit(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err = s.Wait(ctx, operation.ID, options)
+	if err != nil {
+		return operation, err
+	}
+
+	return operation, s.Stream(ctx, operation, fn)
+}
+
+// Submit starts a bulk query. Only one bulk query can run at a time per shop.
+func (s *BulkOperationServiceOp) Submit(ctx context.Context, query string) (*BulkOperation, error) {
+	resp := struct {
+		BulkOperationRunQuery struct {
+			BulkOperation *BulkOperation `json:"bulkOperation"`
+		} `json:"bulkOperationRunQuery"`
+	}{}
+
+	variables := map[string]interface{}{"query": query}
+	err := s.client.GraphQL.Query(ctx, bulkOperationRunQueryMutation, variables, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.BulkOperationRunQuery.BulkOperation == nil {
+		return nil, fmt.Errorf("no bulk operation returned")
+	}
+
+	return resp.BulkOperationRunQuery.BulkOperation, nil
+}
+
+// Current returns the shop's current, i.e. most recent, bulk query. It
+// returns nil if the shop has no bulk operations.
+func (s *BulkOperationServiceOp) Current(ctx context.Context) (*BulkOperation, error) {
+	resp := struct {
+		CurrentBulkOperation *BulkOperation `json:"currentBulkOperation"`
+	}{}
+
+	err := s.client.GraphQL.Query(ctx, currentBulkOperationQuery, nil, &resp)
+	return resp.CurrentBulkOperation, err
+}
+
+// Wait polls the current bulk operation until the operation with the given ID
+// is finished. A BulkOperationError is returned if it did not complete.
+func (s *BulkOperationServiceOp) Wait(ctx context.Context, operationID string, options *BulkOperationOptions) (*BulkOperation, error) {
+	opts := options.withDefaults()
+
+	interval := opts.MinPollInterval
+	for {
+		operation, err := s.Current(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if operation == nil || operation.ID != operationID {
+			return operation, fmt.Errorf("bulk operation %s is no longer the current bulk operation", operationID)
+		}
+
+		switch operation.Status {
+		case BulkOperationCompleted:
+			return operation, nil
+		case BulkOperationCanceled, BulkOperationFailed, BulkOperationExpired:
+			return operation, BulkOperationError{Operation: *operation}
+		}
+
+		err = sleepContext(ctx, interval)
+		if err != nil {
+			return operation, err
+		}
+
+		interval *= 2
+		if interval > opts.MaxPollInterval {
+			interval = opts.MaxPollInterval
+		}
+	}
+}
+
+// Stream downloads the JSONL result of a completed bulk operation and calls fn
+// for every object in it, in order. Objects of nested connections follow the
+// object they belong to and are linked to it through their Parent. Only the
+// current top-level object and its nested objects are kept in memory. Return
+// ErrStopIteration from fn to stop early without an error.
+func (s *BulkOperationServiceOp) Stream(ctx context.Context, operation *BulkOperation, fn func(*BulkObject) error) error {
+	// Operations without results have no URL.
+	if operation.URL == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest("GET", operation.URL, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := s.client.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading bulk operation result: %s", resp.Status)
+	}
+
+	return streamBulkObjects(resp.Body, fn)
+}
+
+// Decodes JSONL bulk operation results from r and calls fn for every object.
+func streamBulkObjects(r io.Reader, fn func(*BulkObject) error) error {
+	reader := bufio.NewReader(r)
+
+	// Objects of the current top-level object and its nested connections.
+	lineage := make(map[string]*BulkObject)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			ids := struct {
+				ID       string `json:"id"`
+				ParentID string `json:"__parentId"`
+			}{}
+
+			decodeErr := json.Unmarshal(line, &ids)
+			if decodeErr != nil {
+				return decodeErr
+			}
+
+			obj := &BulkObject{ID: ids.ID, ParentID: ids.ParentID, Raw: json.RawMessage(line)}
+			if obj.ParentID == "" {
+				lineage = make(map[string]*BulkObject)
+			} else {
+				obj.Parent = lineage[obj.ParentID]
+			}
+			if obj.ID != "" {
+				lineage[obj.ID] = obj
+			}
+
+			fnErr := fn(obj)
+			if fnErr == ErrStopIteration {
+				return nil
+			}
+			if fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
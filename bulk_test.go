@@ -0,0 +1,212 @@
+package goshopify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const bulkResultJSONL = `{"id":"gid://shopify/Product/1","title":"Snowboard"}
+{"id":"gid://shopify/ProductVariant/11","title":"Small","__parentId":"gid://shopify/Product/1"}
+{"id":"gid://shopify/ProductVariant/12","title":"Large","__parentId":"gid://shopify/Product/1"}
+{"id":"gid://shopify/Product/2","title":"Skis"}
+{"id":"gid://shopify/ProductVariant/21","title":"Default","__parentId":"gid://shopify/Product/2"}
+`
+
+// Returns a local stand-in for Shopify that runs a single bulk operation,
+// which completes after the given number of polls.
+func bulkServer(t *testing.T, polls int, finalStatus string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/results.jsonl" {
+			fmt.Fprint(w, bulkResultJSONL)
+			return
+		}
+
+		if r.Header.Get("X-Shopify-Access-Token") != "abcd" {
+			t.Errorf("Request to %v without access token", r.URL.Path)
+		}
+
+		body := new(graphQLRequest)
+		json.NewDecoder(r.Body).Decode(body)
+
+		switch {
+		case strings.Contains(body.Query, "bulkOperationRunQuery"):
+			fmt.Fprint(w, `{"data": {"bulkOperationRunQuery": {"bulkOperation": {"id": "gid://shopify/BulkOperation/1", "status": "CREATED"}, "userErrors": []}}}`)
+		case strings.Contains(body.Query, "currentBulkOperation"):
+			polls--
+			status, url := "RUNNING", ""
+			if polls <= 0 {
+				status = finalStatus
+				if status == BulkOperationCompleted {
+					url = server.URL + "/results.jsonl"
+				}
+			}
+			fmt.Fprintf(w, `{"data": {"currentBulkOperation": {"id": "gid://shopify/BulkOperation/1", "status": %q, "objectCount": "5", "url": %q}}}`, status, url)
+		default:
+			t.Errorf("Unexpected query %q", body.Query)
+		}
+	}))
+	return server
+}
+
+var testBulkOperationOptions = &BulkOperationOptions{
+	MinPollInterval: time.Millisecond,
+	MaxPollInterval: 5 * time.Millisecond,
+}
+
+func TestBulkOperationRun(t *testing.T) {
+	server := bulkServer(t, 3, BulkOperationCompleted)
+	defer server.Close()
+
+	testClient := NewClient(app, "fooshop", "abcd", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	type visited struct {
+		ID, Title, Parent string
+	}
+	var objects []visited
+
+	operation, err := testClient.BulkOperation.Run(context.Background(), `{ products { edges { node { id title } } } }`, testBulkOperationOptions,
+		func(obj *BulkObject) error {
+			v := struct {
+				Title string `json:"title"`
+			}{}
+			err := obj.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			parent := ""
+			if obj.Parent != nil {
+				parent = obj.Parent.ID
+			}
+			objects = append(objects, visited{obj.ID, v.Title, parent})
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("BulkOperation.Run returned error: %v", err)
+	}
+
+	if operation.Status != BulkOperationCompleted || operation.ObjectCount != "5" {
+		t.Errorf("BulkOperation.Run returned %+v, expected a completed operation with 5 objects", operation)
+	}
+
+	expected := []visited{
+		{"gid://shopify/Product/1", "Snowboard", ""},
+		{"gid://shopify/ProductVariant/11", "Small", "gid://shopify/Product/1"},
+		{"gid://shopify/ProductVariant/12", "Large", "gid://shopify/Product/1"},
+		{"gid://shopify/Product/2", "Skis", ""},
+		{"gid://shopify/ProductVariant/21", "Default", "gid://shopify/Product/2"},
+	}
+	if !reflect.DeepEqual(objects, expected) {
+		t.Errorf("BulkOperation.Run streamed %+v, expected %+v", objects, expected)
+	}
+}
+
+func TestBulkOperationRunFailed(t *testing.T) {
+	server := bulkServer(t, 1, BulkOperationFailed)
+	defer server.Close()
+
+	testClient := NewClient(app, "fooshop", "abcd", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	_, err := testClient.BulkOperation.Run(context.Background(), `{ products { edges { node { id } } } }`, testBulkOperationOptions,
+		func(obj *BulkObject) error {
+			t.Errorf("Unexpected object %s", obj.Raw)
+			return nil
+		})
+
+	if e, ok := err.(BulkOperationError); !ok || e.Operation.Status != BulkOperationFailed {
+		t.Errorf("BulkOperation.Run returned %#v, expected a failed BulkOperationError", err)
+	}
+}
+
+func TestBulkOperationWaitCancelled(t *testing.T) {
+	server := bulkServer(t, 1000, BulkOperationCompleted)
+	defer server.Close()
+
+	testClient := NewClient(app, "fooshop", "abcd", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := testClient.BulkOperation.Wait(ctx, "gid://shopify/BulkOperation/1", testBulkOperationOptions)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("BulkOperation.Wait returned %v, expected %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBulkOperationOptionsDefaults(t *testing.T) {
+	cases := []struct {
+		options  *BulkOperationOptions
+		expected BulkOperationOptions
+	}{
+		{nil, defaultBulkOperationOptions},
+		{&BulkOperationOptions{}, defaultBulkOperationOptions},
+		{&BulkOperationOptions{MinPollInterval: 2 * time.Second}, BulkOperationOptions{2 * time.Second, 30 * time.Second}},
+		{&BulkOperationOptions{MaxPollInterval: 10 * time.Second}, BulkOperationOptions{time.Second, 10 * time.Second}},
+		{&BulkOperationOptions{MinPollInterval: -time.Second, MaxPollInterval: -time.Second}, defaultBulkOperationOptions},
+		{&BulkOperationOptions{MinPollInterval: time.Minute}, BulkOperationOptions{time.Minute, time.Minute}},
+	}
+
+	for _, c := range cases {
+		actual := c.options.withDefaults()
+		if actual != c.expected {
+			t.Errorf("BulkOperationOptions(%+v).withDefaults() = %+v, expected %+v", c.options, actual, c.expected)
+		}
+	}
+}
+
+func TestBulkOperationWaitPartialOptions(t *testing.T) {
+	server := bulkServer(t, 1000, BulkOperationCompleted)
+	defer server.Close()
+
+	polls := 0
+	countPolls := func(next DoFunc) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			polls++
+			return next(req)
+		}
+	}
+	testClient := NewClient(app, "fooshop", "abcd", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithMiddleware(countPolls))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Without a MaxPollInterval, the interval still doubles from 5ms instead
+	// of dropping to zero.
+	testClient.BulkOperation.Wait(ctx, "gid://shopify/BulkOperation/1", &BulkOperationOptions{MinPollInterval: 5 * time.Millisecond})
+	if polls > 10 {
+		t.Errorf("BulkOperation.Wait polled %d times in 100ms, expected at most 10", polls)
+	}
+}
+
+func TestStreamBulkObjectsStop(t *testing.T) {
+	count := 0
+	err := streamBulkObjects(strings.NewReader(bulkResultJSONL), func(obj *BulkObject) error {
+		count++
+		if count == 2 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("streamBulkObjects returned error: %v", err)
+	}
+
+	if count != 2 {
+		t.Errorf("streamBulkObjects visited %d objects, expected 2", count)
+	}
+
+	err = streamBulkObjects(strings.NewReader("{invalid"), func(obj *BulkObject) error { return nil })
+	if err == nil {
+		t.Error("streamBulkObjects expected error for invalid JSON")
+	}
+}
@@ -15,6 +15,10 @@ const customersBasePath = "admin/customers"
 // See: https://help.shopify.com/api/reference/customer
 type CustomerService interface {
 	List(context.Context, interface{}) ([]*Customer, error)
+	ListWithPagination(context.Context, interface{}) ([]*Customer, *Pagination, error)
+	ListAll(context.Context, interface{}) ([]*Customer, error)
+	Iterate(context.Context, interface{}, func(*Customer) error) error
+	Stream(context.Context, interface{}, func(*Customer) error) error
 	Count(context.Context, interface{}) (int, error)
 	Get(context.Context, int, interface{}) (*Customer, error)
 }
@@ -64,6 +68,50 @@ func (s *CustomerServiceOp) List(ctx context.Context, options interface{}) ([]*C
 	return resource.Customers, err
 }
 
+// ListWithPagination lists customers and returns the pagination to retrieve
+// the next and previous pages.
+func (s *CustomerServiceOp) ListWithPagination(ctx context.Context, options interface{}) ([]*Customer, *Pagination, error) {
+	path := fmt.Sprintf("%s.json", customersBasePath)
+	resource := new(CustomersResource)
+	pagination, err := s.client.ListWithPagination(ctx, path, resource, options)
+	return resource.Customers, pagination, err
+}
+
+// ListAll lists all customers, fetching page after page until there are no more
+// customers.
+func (s *CustomerServiceOp) ListAll(ctx context.Context, options interface{}) ([]*Customer, error) {
+	var collected []*Customer
+	err := s.Iterate(ctx, options, func(c *Customer) error {
+		collected = append(collected, c)
+		return nil
+	})
+	return collected, err
+}
+
+// Iterate calls fn for every customer, lazily fetching page after page until
+// there are no more customers, fn returns an error or the context is done.
+// Return ErrStopIteration from fn to stop early without an error.
+func (s *CustomerServiceOp) Iterate(ctx context.Context, options interface{}, fn func(*Customer) error) error {
+	return s.iterate(ctx, options, fn, false)
+}
+
+// Stream is like Iterate, but decodes the customers one by one while the pages
+// are read, so only a single customer is kept in memory at a time. fn is called
+// while the response is still open, so the time it takes counts against the
+// timeout of the HTTP client and of the RequestOptions, and keeps the
+// connection busy.
+func (s *CustomerServiceOp) Stream(ctx context.Context, options interface{}, fn func(*Customer) error) error {
+	return s.iterate(ctx, options, fn, true)
+}
+
+func (s *CustomerServiceOp) iterate(ctx context.Context, options interface{}, fn func(*Customer) error, stream bool) error {
+	path := fmt.Sprintf("%s.json", customersBasePath)
+	newElement := func() interface{} { return new(Customer) }
+	return s.client.iterate(ctx, path, "customers", options, newElement, func(c interface{}) error {
+		return fn(c.(*Customer))
+	}, stream)
+}
+
 // Count customers
 func (s *CustomerServiceOp) Count(ctx context.Context, options interface{}) (int, error) {
 	path := fmt.Sprintf("%s/count.json", customersBasePath)
@@ -14,7 +14,7 @@ func TestCustomerList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/customers.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/customers.json",
 		httpmock.NewStringResponder(200, `{"customers": [{"id":1},{"id":2}]}`))
 
 	customers, err := client.Customer.List(context.Background(), nil)
@@ -28,14 +28,38 @@ func TestCustomerList(t *testing.T) {
 	}
 }
 
+func TestCustomerListWithPagination(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/customers.json?page_info=abc",
+		linkResponder(200, `{"customers": [{"id":1},{"id":2}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/customers.json?page_info=xyz>; rel="previous"`))
+
+	customers, pagination, err := client.Customer.ListWithPagination(context.Background(), ListOptions{PageInfo: "abc"})
+	if err != nil {
+		t.Errorf("Customer.ListWithPagination returned error: %v", err)
+	}
+
+	expected := []*Customer{{ID: 1}, {ID: 2}}
+	if !reflect.DeepEqual(customers, expected) {
+		t.Errorf("Customer.ListWithPagination returned %+v, expected %+v", customers, expected)
+	}
+
+	expectedPagination := &Pagination{PreviousPageOptions: &ListOptions{PageInfo: "xyz"}}
+	if !reflect.DeepEqual(pagination, expectedPagination) {
+		t.Errorf("Customer.ListWithPagination returned %+v, expected %+v", pagination, expectedPagination)
+	}
+}
+
 func TestCustomerCount(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/customers/count.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/customers/count.json",
 		httpmock.NewStringResponder(200, `{"count": 5}`))
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/customers/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/customers/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
 		httpmock.NewStringResponder(200, `{"count": 2}`))
 
 	cnt, err := client.Customer.Count(context.Background(), nil)
@@ -64,7 +88,7 @@ func TestCustomerGet(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/customers/1.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/customers/1.json",
 		httpmock.NewBytesResponder(200, loadFixture("customer.json")))
 
 	customer, err := client.Customer.Get(context.Background(), 1, nil)
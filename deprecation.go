@@ -0,0 +1,135 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Deprecation is a deprecated endpoint that was called, along with the reason
+// Shopify reported for it.
+type Deprecation struct {
+	// The method and path of the endpoint with IDs replaced, e.g.
+	// "GET /admin/api/2024-10/orders/:id.json".
+	Endpoint string `json:"endpoint"`
+
+	// The value of the X-Shopify-API-Deprecated-Reason header, usually a link
+	// to the changelog entry.
+	Reason string `json:"reason"`
+
+	// The number of responses with this endpoint and reason.
+	Count int `json:"count"`
+
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// DeprecationReporter collects the distinct deprecated endpoints and reasons
+// seen in the X-Shopify-API-Deprecated-Reason headers of responses, e.g. to
+// find the calls that need to change before upgrading the API version. It can
+// be shared between clients and is safe for concurrent use.
+//
+// A DeprecationReporter is an http.Handler that serves the report as JSON, so
+// it can be mounted on a debug endpoint.
+type DeprecationReporter struct {
+	mu           sync.Mutex
+	deprecations map[deprecationKey]*Deprecation
+}
+
+type deprecationKey struct {
+	endpoint string
+	reason   string
+}
+
+// NewDeprecationReporter returns an empty DeprecationReporter.
+func NewDeprecationReporter() *DeprecationReporter {
+	return &DeprecationReporter{}
+}
+
+// WithDeprecationReporter makes the client report the deprecated endpoints it
+// calls to the given reporter.
+func WithDeprecationReporter(reporter *DeprecationReporter) Option {
+	return func(c *Client) {
+		c.deprecations = reporter
+	}
+}
+
+// Report returns the deprecations seen so far, ordered by endpoint and
+// reason.
+func (r *DeprecationReporter) Report() []Deprecation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := make([]Deprecation, 0, len(r.deprecations))
+	for _, d := range r.deprecations {
+		report = append(report, *d)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Endpoint != report[j].Endpoint {
+			return report[i].Endpoint < report[j].Endpoint
+		}
+		return report[i].Reason < report[j].Reason
+	})
+
+	return report
+}
+
+// Reset discards the deprecations seen so far.
+func (r *DeprecationReporter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deprecations = nil
+}
+
+// ServeHTTP writes the report as a JSON array.
+func (r *DeprecationReporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.Report())
+}
+
+// Records the deprecation reported in a response to the given request, if
+// any.
+func (r *DeprecationReporter) observe(req *http.Request, resp *http.Response) {
+	reason := resp.Header.Get(DeprecatedReasonHeader)
+	if reason == "" {
+		return
+	}
+
+	key := deprecationKey{
+		endpoint: req.Method + " " + endpointTemplate(req.URL.Path),
+		reason:   reason,
+	}
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deprecations == nil {
+		r.deprecations = make(map[deprecationKey]*Deprecation)
+	}
+
+	d, ok := r.deprecations[key]
+	if !ok {
+		d = &Deprecation{Endpoint: key.endpoint, Reason: key.reason, FirstSeen: now}
+		r.deprecations[key] = d
+	}
+	d.Count++
+	d.LastSeen = now
+}
+
+// Replaces the IDs in a path with :id, e.g. /admin/orders/1.json becomes
+// /admin/orders/:id.json.
+func endpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		name := strings.TrimSuffix(segment, ".json")
+		if isID(name) {
+			segments[i] = ":id" + segment[len(name):]
+		}
+	}
+	return strings.Join(segments, "/")
+}
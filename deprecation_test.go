@@ -0,0 +1,111 @@
+package goshopify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func deprecatedResponder(body, reason string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(200, body)
+		if reason != "" {
+			resp.Header.Set(DeprecatedReasonHeader, reason)
+		}
+		return resp, nil
+	}
+}
+
+func TestEndpointTemplate(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected string
+	}{
+		{"/admin/api/2024-10/orders.json", "/admin/api/2024-10/orders.json"},
+		{"/admin/api/2024-10/orders/1.json", "/admin/api/2024-10/orders/:id.json"},
+		{"/admin/api/2024-10/products/1/images/2.json", "/admin/api/2024-10/products/:id/images/:id.json"},
+		{"/admin/api/2024-10/products/1/variants/count.json", "/admin/api/2024-10/products/:id/variants/count.json"},
+	}
+
+	for _, c := range cases {
+		actual := endpointTemplate(c.in)
+		if actual != c.expected {
+			t.Errorf("endpointTemplate(%q) = %q, expected %q", c.in, actual, c.expected)
+		}
+	}
+}
+
+func TestDeprecationReporter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	reporter := NewDeprecationReporter()
+	testClient := NewClient(app, "fooshop", "abcd", WithDeprecationReporter(reporter))
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/1.json",
+		deprecatedResponder(`{"order": {"id": 1}}`, "https://shopify.dev/changelog/orders"))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/2.json",
+		deprecatedResponder(`{"order": {"id": 2}}`, "https://shopify.dev/changelog/orders"))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/count.json",
+		deprecatedResponder(`{"count": 3}`, "https://shopify.dev/changelog/products"))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/customers/count.json",
+		deprecatedResponder(`{"count": 3}`, ""))
+
+	ctx := context.Background()
+	for _, id := range []int{1, 2, 1} {
+		_, err := testClient.Order.Get(ctx, id, nil)
+		if err != nil {
+			t.Fatalf("Order.Get returned error: %v", err)
+		}
+	}
+	for _, count := range []func(context.Context, interface{}) (int, error){testClient.Product.Count, testClient.Customer.Count} {
+		_, err := count(ctx, nil)
+		if err != nil {
+			t.Fatalf("Count returned error: %v", err)
+		}
+	}
+
+	report := reporter.Report()
+	if len(report) != 2 {
+		t.Fatalf("Report returned %d deprecations, expected 2: %+v", len(report), report)
+	}
+
+	expected := []struct {
+		endpoint string
+		reason   string
+		count    int
+	}{
+		{"GET /admin/api/2024-10/orders/:id.json", "https://shopify.dev/changelog/orders", 3},
+		{"GET /admin/api/2024-10/products/count.json", "https://shopify.dev/changelog/products", 1},
+	}
+	for i, e := range expected {
+		d := report[i]
+		if d.Endpoint != e.endpoint || d.Reason != e.reason || d.Count != e.count {
+			t.Errorf("Report()[%d] = %+v, expected %s %s seen %d times", i, d, e.endpoint, e.reason, e.count)
+		}
+		if d.FirstSeen.IsZero() || d.LastSeen.Before(d.FirstSeen) {
+			t.Errorf("Report()[%d] has invalid timestamps: %+v", i, d)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	reporter.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/shopify/deprecations", nil))
+
+	var served []Deprecation
+	err := json.NewDecoder(rec.Body).Decode(&served)
+	if err != nil {
+		t.Fatalf("ServeHTTP wrote invalid JSON: %v", err)
+	}
+	if len(served) != 2 || served[0].Endpoint != expected[0].endpoint || served[0].Count != 3 {
+		t.Errorf("ServeHTTP wrote %+v", served)
+	}
+
+	reporter.Reset()
+	if len(reporter.Report()) != 0 {
+		t.Error("Reset did not discard the deprecations")
+	}
+}
@@ -6,12 +6,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -29,6 +33,10 @@ type App struct {
 	RedirectUrl string
 	Scope       string
 	Password    string
+
+	// Secrets that webhooks are also verified against, e.g. the previous
+	// ApiSecret while it is being rotated.
+	PreviousApiSecrets []string
 }
 
 // Client manages communication with the Shopify API.
@@ -36,6 +44,13 @@ type Client struct {
 	// HTTP client used to communicate with the DO API.
 	Client *http.Client
 
+	// Policy used to retry failed requests. The zero value disables retries.
+	Retry RetryPolicy
+
+	// Tracks the API call limit of the shop and optionally throttles
+	// requests. Can be shared between clients. Nil disables tracking.
+	RateLimiter *RateLimiter
+
 	// App settings
 	app App
 
@@ -47,6 +62,33 @@ type Client struct {
 	// A permanent access token
 	token string
 
+	// Admin API version injected into the request paths
+	apiVersion string
+
+	// User-Agent header sent with every request
+	userAgent string
+
+	// Logger for a record per API call, may be nil
+	logger *slog.Logger
+
+	// Level at which the bodies of unsuccessful responses are logged
+	errorBodyLogLevel slog.Level
+
+	// Metrics that receive an observation per API call, may be nil
+	metrics Metrics
+
+	// Collects the deprecated endpoints that are called, may be nil
+	deprecations *DeprecationReporter
+
+	// Configuration error reported by every request, e.g. an invalid base URL
+	err error
+
+	// Middleware wrapped around every request, outermost first
+	middlewares []Middleware
+
+	mu               sync.Mutex
+	servedAPIVersion string
+
 	// Services used for communicating with the API
 	Product   ProductService
 	Customer  CustomerService
@@ -56,6 +98,9 @@ type Client struct {
 	Variant   VariantService
 	Image     ImageService
 	Metafield MetafieldService
+	GraphQL   GraphQLService
+
+	BulkOperation BulkOperationService
 }
 
 // A general response error that follows a similar layout to Shopify's response
@@ -64,6 +109,16 @@ type ResponseError struct {
 	Status  int
 	Message string
 	Errors  []string
+
+	// The messages per field for validation errors. Fields of nested objects
+	// are joined with dots, e.g. "variants.0.price".
+	FieldErrors map[string][]string
+
+	// The X-Request-Id of the response, which Shopify support asks for.
+	RequestID string
+
+	// The raw body of the response.
+	Body []byte
 }
 
 func (e ResponseError) Error() string {
@@ -88,16 +143,83 @@ type RateLimitError struct {
 	RetryAfter int
 }
 
+func (e RateLimitError) Unwrap() error {
+	return e.ResponseError
+}
+
+// UnauthorizedError is returned for 401 responses, e.g. when the access token
+// is invalid.
+type UnauthorizedError struct {
+	ResponseError
+}
+
+func (e UnauthorizedError) Unwrap() error {
+	return e.ResponseError
+}
+
+// ForbiddenError is returned for 403 responses, e.g. when the app lacks the
+// access scope for a resource.
+type ForbiddenError struct {
+	ResponseError
+}
+
+func (e ForbiddenError) Unwrap() error {
+	return e.ResponseError
+}
+
+// NotFoundError is returned for 404 responses.
+type NotFoundError struct {
+	ResponseError
+}
+
+func (e NotFoundError) Unwrap() error {
+	return e.ResponseError
+}
+
+// ValidationError is returned for 422 responses, when the submitted resource
+// is invalid. The messages per field are in FieldErrors.
+type ValidationError struct {
+	ResponseError
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.ResponseError
+}
+
+// ServerError is returned for 5xx responses.
+type ServerError struct {
+	ResponseError
+}
+
+func (e ServerError) Unwrap() error {
+	return e.ResponseError
+}
+
 // Creates an API request. A relative URL can be provided in urlStr, which will
 // be resolved to the BaseURL of the Client. Relative URLS should always be
-// specified without a preceding slash. If specified, the value pointed to by
-// body is JSON encoded and included as the request body.
+// specified without a preceding slash. Relative admin paths are versioned with
+// the client's API version. If specified, the value pointed to by body is JSON
+// encoded and included as the request body.
 func (c *Client) NewRequest(method, urlStr string, body, options interface{}) (*http.Request, error) {
+	return c.newRequest(context.Background(), method, urlStr, body, options)
+}
+
+// Same as NewRequest, but the request is created with the given context, whose
+// RequestOptions are applied to the request.
+func (c *Client) newRequest(ctx context.Context, method, urlStr string, body, options interface{}) (*http.Request, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	rel, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, err
 	}
 
+	if !rel.IsAbs() && rel.Host == "" {
+		rel.Path = versionedPath(rel.Path, c.apiVersionFor(ctx))
+	}
+
 	// Make the full url based on the relative path
 	u := c.baseURL.ResolveReference(rel)
 
@@ -130,26 +252,39 @@ func (c *Client) NewRequest(method, urlStr string, body, options interface{}) (*
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("User-Agent", UserAgent)
+	req.Header.Add("User-Agent", c.userAgent)
 	if c.token != "" {
 		req.Header.Add("X-Shopify-Access-Token", c.token)
 	} else if c.app.Password != "" {
 		req.SetBasicAuth(c.app.ApiKey, c.app.Password)
 	}
+	requestOptionsFrom(ctx).apply(req)
 	return req, nil
 }
 
 // NewClient returns a new Shopify API client with an already authenticated shopname and
-// token.
-func NewClient(app App, shopName, token string) *Client {
+// token. The client can be configured further with options.
+func NewClient(app App, shopName, token string, opts ...Option) *Client {
 	httpClient := http.DefaultClient
 
 	baseURL, _ := url.Parse(ShopBaseUrl(shopName))
 
-	c := &Client{Client: httpClient, app: app, baseURL: baseURL, token: token}
+	c := &Client{
+		Client:      httpClient,
+		Retry:       DefaultRetryPolicy,
+		RateLimiter: NewRateLimiter(),
+		app:         app,
+		baseURL:     baseURL,
+		token:       token,
+		apiVersion:  DefaultAPIVersion,
+		userAgent:   UserAgent,
+
+		errorBodyLogLevel: slog.LevelDebug,
+	}
 	c.Product = &ProductServiceOp{client: c}
 	c.Customer = &CustomerServiceOp{client: c}
 	c.Order = &OrderServiceOp{client: c}
@@ -158,6 +293,12 @@ func NewClient(app App, shopName, token string) *Client {
 	c.Variant = &VariantServiceOp{client: c}
 	c.Image = &ImageServiceOp{client: c}
 	c.Metafield = &MetafieldServiceOp{client: c}
+	c.GraphQL = &GraphQLServiceOp{client: c}
+	c.BulkOperation = &BulkOperationServiceOp{client: c}
+
+	for _, opt := range opts {
+		opt(c)
+	}
 
 	return c
 }
@@ -165,36 +306,133 @@ func NewClient(app App, shopName, token string) *Client {
 // Do sends an API request and populates the given interface with the parsed
 // response. It does not make much sense to call Do without a prepared
 // interface instance.
+//
+// The request passes through the client's middleware chain. Failed requests
+// are retried according to the client's RetryPolicy. Retries stop as soon as
+// the request's context is done. The Timeout and DisableRetries of the
+// RequestOptions in the request's context are honored.
 func (c *Client) Do(req *http.Request, v interface{}) error {
+	_, err := c.doGetHeaders(req, v)
+	return err
+}
+
+// Same as Do, but also returns the headers of the final response.
+func (c *Client) doGetHeaders(req *http.Request, v interface{}) (http.Header, error) {
+	return c.doDecode(req, func(body io.Reader) error {
+		if v == nil {
+			return nil
+		}
+		decoder := json.NewDecoder(body)
+		return decoder.Decode(&v)
+	})
+}
+
+// Same as doGetHeaders, but the body of a successful response is decoded by
+// the given function.
+func (c *Client) doDecode(req *http.Request, decode func(body io.Reader) error) (http.Header, error) {
+	if timeout := requestOptionsFrom(req.Context()).Timeout; timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := c.handler()(req)
+	if resp == nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if r, ok := req.Context().Value(responseKey{}).(*Response); ok {
+		r.fill(resp)
+	}
+
+	if c.deprecations != nil {
+		c.deprecations.observe(req, resp)
+	}
+
+	if err != nil {
+		return resp.Header, err
+	}
+
+	return resp.Header, decode(resp.Body)
+}
+
+// Sends a single attempt of an API request. This is the innermost DoFunc of
+// the middleware chain.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
 	resp, err := c.Client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	err = CheckResponseError(resp)
+	if c.RateLimiter != nil {
+		c.RateLimiter.update(req.URL.Host, resp)
+	}
+
+	if version := resp.Header.Get(APIVersionHeader); version != "" {
+		c.mu.Lock()
+		c.servedAPIVersion = version
+		c.mu.Unlock()
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	// Buffer the body of unsuccessful responses, so it can be read again by
+	// middleware after the error has been decoded from it.
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if v != nil {
-		decoder := json.NewDecoder(resp.Body)
-		err := decoder.Decode(&v)
-		if err != nil {
-			return err
-		}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	err = CheckResponseError(resp)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, err
+}
+
+// CallLimit returns the estimated fill level of the shop's API call bucket, as
+// tracked by the client's RateLimiter. The zero value is returned when no
+// response has been received yet.
+func (c *Client) CallLimit() CallLimit {
+	if c.RateLimiter == nil {
+		return CallLimit{}
 	}
+	limit, _ := c.RateLimiter.CallLimit(c.baseURL.Host)
+	return limit
+}
 
-	return nil
+// GraphQLThrottleStatus returns the estimated throttle status of the shop's
+// GraphQL query points, as tracked by the client's RateLimiter. The zero value
+// is returned when no GraphQL response has been received yet.
+func (c *Client) GraphQLThrottleStatus() GraphQLThrottleStatus {
+	if c.RateLimiter == nil {
+		return GraphQLThrottleStatus{}
+	}
+	status, _ := c.RateLimiter.GraphQLStatus(c.baseURL.Host)
+	return status
 }
 
 func wrapSpecificError(r *http.Response, err ResponseError) error {
-	if err.Status == 429 {
+	switch {
+	case err.Status == 429:
 		f, _ := strconv.ParseFloat(r.Header.Get("retry-after"), 64)
 		return RateLimitError{
 			ResponseError: err,
 			RetryAfter:    int(f),
 		}
+	case err.Status == 401:
+		return UnauthorizedError{err}
+	case err.Status == 403:
+		return ForbiddenError{err}
+	case err.Status == 404:
+		return NotFoundError{err}
+	case err.Status == 422:
+		return ValidationError{err}
+	case err.Status >= 500:
+		return ServerError{err}
 	}
 	return err
 }
@@ -210,18 +448,28 @@ func CheckResponseError(r *http.Response) error {
 		Errors interface{} `json:"errors"`
 	}{}
 
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&shopifyError)
+	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return err
 	}
 
 	// Create the response error from the Shopify error.
 	responseError := ResponseError{
-		Status:  r.StatusCode,
-		Message: shopifyError.Error,
+		Status:    r.StatusCode,
+		RequestID: r.Header.Get(RequestIDHeader),
+		Body:      body,
 	}
 
+	// Responses that don't come from Shopify itself, e.g. an HTML 502 page
+	// of a proxy, only have their status to go on.
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	err = decoder.Decode(&shopifyError)
+	if err != nil {
+		responseError.Message = http.StatusText(r.StatusCode)
+		return wrapSpecificError(r, responseError)
+	}
+	responseError.Message = shopifyError.Error
+
 	// If the errors field is not filled out, we can return here.
 	if shopifyError.Errors == nil {
 		return wrapSpecificError(r, responseError)
@@ -235,8 +483,11 @@ func CheckResponseError(r *http.Response) error {
 	//     ]
 	//   }
 	// }
-	// This structure is flattened to a single array:
+	// This structure is kept per field in FieldErrors and flattened to a
+	// single array:
 	// [ "title: something is wrong" ]
+	// Errors of nested objects are kept with dotted paths, e.g.
+	// "variants.0.price".
 	//
 	// Unfortunately, "errors" can also be a single string so we have to deal
 	// with that. Lots of reflection :-(
@@ -252,21 +503,24 @@ func CheckResponseError(r *http.Response) error {
 		}
 		responseError.Message = strings.Join(responseError.Errors, ", ")
 	} else if kind == reflect.Map {
-		// A map, parse each error for each key in the map.
-		// json always serializes into map[string]interface{} for objects
-		for k, v := range shopifyError.Errors.(map[string]interface{}) {
-			// Check to make sure the interface is a slice
-			// json always serializes JSON arrays into []interface{}
-			if reflect.TypeOf(v).Kind() == reflect.Slice {
-				for _, elem := range v.([]interface{}) {
-					// If the primary message of the response error is not set, use
-					// any message.
-					if responseError.Message == "" {
-						responseError.Message = fmt.Sprint(elem)
-					}
-					topicAndElem := fmt.Sprintf("%v: %v", k, elem)
-					responseError.Errors = append(responseError.Errors, topicAndElem)
+		responseError.FieldErrors = make(map[string][]string)
+		collectFieldErrors(responseError.FieldErrors, "", shopifyError.Errors)
+
+		fields := make([]string, 0, len(responseError.FieldErrors))
+		for field := range responseError.FieldErrors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			for _, message := range responseError.FieldErrors[field] {
+				// If the primary message of the response error is not set,
+				// use the first message.
+				if responseError.Message == "" {
+					responseError.Message = message
 				}
+				topicAndElem := fmt.Sprintf("%v: %v", field, message)
+				responseError.Errors = append(responseError.Errors, topicAndElem)
 			}
 		}
 	}
@@ -274,8 +528,46 @@ func CheckResponseError(r *http.Response) error {
 	return wrapSpecificError(r, responseError)
 }
 
+// Collects the messages of a decoded errors object per dotted field path.
+// json always serializes objects into map[string]interface{} and arrays into
+// []interface{}.
+func collectFieldErrors(fieldErrors map[string][]string, path string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range v {
+			collectFieldErrors(fieldErrors, joinFieldPath(path, k), elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			switch elem.(type) {
+			case map[string]interface{}, []interface{}:
+				// Errors of the nested object at index i
+				collectFieldErrors(fieldErrors, joinFieldPath(path, strconv.Itoa(i)), elem)
+			default:
+				fieldErrors[path] = append(fieldErrors[path], fmt.Sprint(elem))
+			}
+		}
+	case nil:
+		// A field without messages
+	default:
+		fieldErrors[path] = append(fieldErrors[path], fmt.Sprint(v))
+	}
+}
+
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
 // General list options that can be used for most collections of entities.
+//
+// Newer API versions paginate with a cursor instead of a page number. The
+// cursor is returned as part of a Pagination by the ListWithPagination
+// methods and is passed back in PageInfo.
 type ListOptions struct {
+	PageInfo     string    `url:"page_info,omitempty"`
 	Page         int       `url:"page,omitempty"`
 	Limit        int       `url:"limit,omitempty"`
 	SinceID      int       `url:"since_id,omitempty"`
@@ -284,6 +576,7 @@ type ListOptions struct {
 	UpdatedAtMin time.Time `url:"updated_at_min,omitempty"`
 	UpdatedAtMax time.Time `url:"updated_at_max,omitempty"`
 	Order        string    `url:"order,omitempty"`
+	Fields       string    `url:"fields,omitempty"`
 }
 
 // General count options that can be used for most collection counts.
@@ -312,19 +605,31 @@ func (c *Client) Count(ctx context.Context, path string, options interface{}) (i
 // parameters like created_at_min
 // Any data returned from Shopify will be marshalled into resource argument.
 func (c *Client) CreateAndDo(ctx context.Context, method, path string, data, options, resource interface{}) error {
-	req, err := c.NewRequest(method, path, data, options)
+	_, err := c.createAndDoGetHeaders(ctx, method, path, data, options, resource)
+	return err
+}
+
+// Same as CreateAndDo, but also returns the headers of the response.
+func (c *Client) createAndDoGetHeaders(ctx context.Context, method, path string, data, options, resource interface{}) (http.Header, error) {
+	req, err := c.newRequest(ctx, method, path, data, options)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	req = req.WithContext(ctx)
+	return c.doGetHeaders(req, resource)
+}
 
-	err = c.Do(req, resource)
+// ListWithPagination performs a GET request for the given path and saves the
+// result in the given resource. The returned Pagination contains the options
+// to retrieve the next and previous pages, based on the Link header of the
+// response.
+func (c *Client) ListWithPagination(ctx context.Context, path string, resource, options interface{}) (*Pagination, error) {
+	headers, err := c.createAndDoGetHeaders(ctx, "GET", path, nil, options, resource)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return extractPagination(headers.Get("Link"))
 }
 
 // Get performs a GET request for the given path and saves the result in the
@@ -219,12 +219,18 @@ func TestDo(t *testing.T) {
 		{
 			"foo/2",
 			httpmock.NewStringResponder(404, `{"error": "does not exist"}`),
-			ResponseError{Status: 404, Message: "does not exist"},
+			NotFoundError{ResponseError{Status: 404, Message: "does not exist", Body: []byte(`{"error": "does not exist"}`)}},
 		},
 		{
 			"foo/3",
 			httpmock.NewStringResponder(400, `{"errors": {"title": ["wrong"]}}`),
-			ResponseError{Status: 400, Message: "wrong", Errors: []string{"title: wrong"}},
+			ResponseError{
+				Status:      400,
+				Message:     "wrong",
+				Errors:      []string{"title: wrong"},
+				FieldErrors: map[string][]string{"title": {"wrong"}},
+				Body:        []byte(`{"errors": {"title": ["wrong"]}}`),
+			},
 		},
 		{
 			"foo/4",
@@ -248,11 +254,15 @@ func TestDo(t *testing.T) {
 				ResponseError: ResponseError{
 					Status:  429,
 					Message: "Exceeded 2 calls per second for api client. Reduce request rates to resume uninterrupted service.",
+					Body:    []byte(`{"errors":"Exceeded 2 calls per second for api client. Reduce request rates to resume uninterrupted service."}`),
 				},
 			},
 		},
 	}
 
+	// Retries are covered in retry_test.go
+	client.Retry = RetryPolicy{}
+
 	for _, c := range cases {
 		shopUrl := fmt.Sprintf("https://fooshop.myshopify.com/%v", c.url)
 		httpmock.RegisterResponder("GET", shopUrl, c.responder)
@@ -472,7 +482,11 @@ func TestCheckResponseError(t *testing.T) {
 		},
 		{
 			httpmock.NewStringResponse(400, `{error:bad request}`),
-			errors.New("invalid character 'e' looking for beginning of object key string"),
+			ResponseError{Status: 400, Message: "Bad Request"},
+		},
+		{
+			httpmock.NewStringResponse(502, `<html><body>502 Bad Gateway</body></html>`),
+			ServerError{ResponseError{Status: 502, Message: "Bad Gateway"}},
 		},
 	}
 
@@ -484,6 +498,78 @@ func TestCheckResponseError(t *testing.T) {
 	}
 }
 
+func TestCheckResponseErrorFieldErrors(t *testing.T) {
+	resp := httpmock.NewStringResponse(422, `{"errors": {
+		"title": ["can't be blank"],
+		"variants": [
+			{"price": ["must be greater than or equal to 0"]},
+			{"sku": ["has already been taken", "is too long"]}
+		],
+		"options": {"size": {"values": ["can't be empty"]}}
+	}}`)
+
+	err := CheckResponseError(resp)
+
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("CheckResponseError(): expected ValidationError, actual %#v", err)
+	}
+
+	expected := map[string][]string{
+		"title":               {"can't be blank"},
+		"variants.0.price":    {"must be greater than or equal to 0"},
+		"variants.1.sku":      {"has already been taken", "is too long"},
+		"options.size.values": {"can't be empty"},
+	}
+	if !reflect.DeepEqual(validationErr.FieldErrors, expected) {
+		t.Errorf("FieldErrors: expected %v, actual %v", expected, validationErr.FieldErrors)
+	}
+
+	expectedErrors := []string{
+		"options.size.values: can't be empty",
+		"title: can't be blank",
+		"variants.0.price: must be greater than or equal to 0",
+		"variants.1.sku: has already been taken",
+		"variants.1.sku: is too long",
+	}
+	if !reflect.DeepEqual(validationErr.Errors, expectedErrors) {
+		t.Errorf("Errors: expected %v, actual %v", expectedErrors, validationErr.Errors)
+	}
+
+	if validationErr.Message != "can't be empty" {
+		t.Errorf("Message: expected the first message, actual %q", validationErr.Message)
+	}
+}
+
+func TestCheckResponseErrorTypes(t *testing.T) {
+	cases := []struct {
+		status int
+		target interface{}
+	}{
+		{401, new(UnauthorizedError)},
+		{403, new(ForbiddenError)},
+		{404, new(NotFoundError)},
+		{422, new(ValidationError)},
+		{429, new(RateLimitError)},
+		{500, new(ServerError)},
+		{503, new(ServerError)},
+		{400, new(ResponseError)},
+	}
+
+	for _, c := range cases {
+		err := CheckResponseError(httpmock.NewStringResponse(c.status, `{"errors": "oops"}`))
+
+		if !errors.As(err, c.target) {
+			t.Errorf("CheckResponseError(%d): expected %T, actual %#v", c.status, c.target, err)
+		}
+
+		var responseErr ResponseError
+		if !errors.As(err, &responseErr) || responseErr.Status != c.status || responseErr.Message != "oops" {
+			t.Errorf("CheckResponseError(%d): expected to unwrap to a ResponseError, actual %#v", c.status, err)
+		}
+	}
+}
+
 func TestCount(t *testing.T) {
 	setup()
 	defer teardown()
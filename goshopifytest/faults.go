@@ -0,0 +1,188 @@
+package goshopifytest
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+)
+
+// FaultKind is a kind of failure a Server can inject.
+type FaultKind int
+
+const (
+	// FaultNone serves the request normally. It can be scheduled between
+	// other faults.
+	FaultNone FaultKind = iota
+
+	// FaultRateLimit responds with 429 Too Many Requests and a Retry-After
+	// header.
+	FaultRateLimit
+
+	// FaultServerError responds with a 5xx status.
+	FaultServerError
+
+	// FaultDelay waits before serving the request normally, or until the
+	// request is canceled.
+	FaultDelay
+
+	// FaultTruncate serves the request but cuts off the body halfway.
+	FaultTruncate
+
+	// FaultReset resets the connection without responding.
+	FaultReset
+)
+
+// Fault describes a failure to inject into a response.
+type Fault struct {
+	Kind FaultKind
+
+	// The status of a FaultServerError. Defaults to 503.
+	Status int
+
+	// The Retry-After of a FaultRateLimit, sent in seconds. Shopify usually
+	// sends 2.0.
+	RetryAfter time.Duration
+
+	// The wait of a FaultDelay.
+	Delay time.Duration
+
+	// Match restricts the fault to the requests it returns true for. Nil
+	// matches all requests.
+	Match func(*http.Request) bool
+}
+
+func (f Fault) matches(r *http.Request) bool {
+	return f.Match == nil || f.Match(r)
+}
+
+type faultState struct {
+	scheduled   []Fault
+	probability float64
+	random      []Fault
+	rand        *rand.Rand
+	injected    int
+}
+
+// InjectFaults schedules faults for the next requests. Each fault is injected
+// into the first request it matches, in order, so that e.g. a burst of three
+// FaultServerError is injected into the next three requests. Requests that
+// don't match the next scheduled fault are served normally.
+func (s *Server) InjectFaults(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.scheduled = append(s.faults.scheduled, faults...)
+}
+
+// InjectRandomFaults injects one of the faults, picked at random, into
+// requests with the given probability between 0 and 1. Scheduled faults take
+// precedence. The seed makes the sequence of faults reproducible.
+func (s *Server) InjectRandomFaults(probability float64, seed int64, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.probability = probability
+	s.faults.random = faults
+	s.faults.rand = rand.New(rand.NewSource(seed))
+}
+
+// ClearFaults removes the scheduled and random faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faultState{injected: s.faults.injected}
+}
+
+// InjectedFaults returns the number of faults injected so far, not counting
+// FaultNone.
+func (s *Server) InjectedFaults() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.faults.injected
+}
+
+// Returns the fault to inject into a request, if any. Must be called with the
+// lock held.
+func (s *Server) nextFault(r *http.Request) (Fault, bool) {
+	f := &s.faults
+
+	var fault Fault
+	switch {
+	case len(f.scheduled) > 0 && f.scheduled[0].matches(r):
+		fault = f.scheduled[0]
+		f.scheduled = f.scheduled[1:]
+	case len(f.random) > 0 && f.rand.Float64() < f.probability:
+		fault = f.random[f.rand.Intn(len(f.random))]
+		if !fault.matches(r) {
+			return Fault{}, false
+		}
+	default:
+		return Fault{}, false
+	}
+
+	if fault.Kind == FaultNone {
+		return Fault{}, false
+	}
+
+	f.injected++
+	return fault, true
+}
+
+// Injects a fault into the response to a request. It returns false if the
+// request should still be served normally.
+func (s *Server) injectFault(w http.ResponseWriter, r *http.Request, fault Fault) bool {
+	switch fault.Kind {
+	case FaultRateLimit:
+		w.Header().Set("Retry-After", strconv.FormatFloat(fault.RetryAfter.Seconds(), 'f', 1, 64))
+		writeJSON(w, http.StatusTooManyRequests, object{
+			"errors": "Exceeded 2 calls per second for api client. Reduce request rates to resume uninterrupted service.",
+		})
+	case FaultServerError:
+		status := fault.Status
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, object{"errors": http.StatusText(status)})
+	case FaultDelay:
+		timer := time.NewTimer(fault.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return false
+		case <-r.Context().Done():
+		}
+	case FaultTruncate:
+		rec := httptest.NewRecorder()
+		for k, v := range w.Header() {
+			rec.Header()[k] = v
+		}
+		s.serve(rec, r)
+
+		body := rec.Body.Bytes()
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(rec.Code)
+		w.Write(body[:len(body)/2])
+	case FaultReset:
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			return false
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			return false
+		}
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			// Send a RST instead of a FIN.
+			tcp.SetLinger(0)
+		}
+		conn.Close()
+	default:
+		return false
+	}
+
+	return true
+}
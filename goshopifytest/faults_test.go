@@ -0,0 +1,188 @@
+package goshopifytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+var testRetryPolicy = goshopify.RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func TestFaultsRetried(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.InjectFaults(
+		Fault{Kind: FaultRateLimit},
+		Fault{Kind: FaultServerError, Status: 502},
+		Fault{Kind: FaultReset},
+	)
+
+	client := server.NewClient(goshopify.WithRetry(testRetryPolicy))
+	shop, err := client.Shop.Get(context.Background(), nil)
+	if err != nil || shop.ID != 1 {
+		t.Errorf("Shop.Get returned %+v, %v", shop, err)
+	}
+
+	if server.InjectedFaults() != 3 {
+		t.Errorf("Injected %d faults, expected 3", server.InjectedFaults())
+	}
+}
+
+func TestFaultRateLimit(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.InjectFaults(Fault{Kind: FaultRateLimit, RetryAfter: 2 * time.Second})
+
+	client := server.NewClient(goshopify.WithRetry(goshopify.RetryPolicy{}))
+	_, err := client.Shop.Get(context.Background(), nil)
+
+	var rateLimitErr goshopify.RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 2 {
+		t.Errorf("Shop.Get returned %#v, expected a RateLimitError with RetryAfter 2", err)
+	}
+}
+
+func TestFaultServerErrorBurst(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	burst := Fault{Kind: FaultServerError}
+	server.InjectFaults(burst, burst, burst, burst, burst)
+
+	client := server.NewClient(goshopify.WithRetry(testRetryPolicy))
+	_, err := client.Shop.Get(context.Background(), nil)
+
+	var serverErr goshopify.ServerError
+	if !errors.As(err, &serverErr) || serverErr.Status != 503 {
+		t.Errorf("Shop.Get returned %#v, expected a ServerError with status 503", err)
+	}
+
+	// The last fault of the burst is left for the next request.
+	_, err = client.Shop.Get(context.Background(), nil)
+	if err != nil {
+		t.Errorf("Shop.Get returned %v, expected the retry to succeed", err)
+	}
+}
+
+func TestFaultDelay(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.InjectFaults(Fault{Kind: FaultDelay, Delay: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := server.NewClient(goshopify.WithRetry(testRetryPolicy))
+	_, err := client.Shop.Get(ctx, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shop.Get returned %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	server.InjectFaults(Fault{Kind: FaultDelay, Delay: 10 * time.Millisecond})
+	_, err = client.Shop.Get(context.Background(), nil)
+	if err != nil {
+		t.Errorf("Shop.Get returned %v, expected the delayed request to succeed", err)
+	}
+}
+
+func TestFaultTruncate(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.InjectFaults(Fault{Kind: FaultTruncate})
+
+	client := server.NewClient(goshopify.WithRetry(testRetryPolicy))
+	_, err := client.Shop.Get(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpected EOF") {
+		t.Errorf("Shop.Get returned %v, expected unexpected EOF", err)
+	}
+}
+
+func TestFaultReset(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.InjectFaults(Fault{Kind: FaultReset}, Fault{Kind: FaultReset})
+
+	client := server.NewClient(goshopify.WithRetry(goshopify.RetryPolicy{}))
+	err := client.Post(context.Background(), "admin/webhooks.json", goshopify.WebhookResource{}, nil)
+
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Errorf("Post returned %#v, expected a *url.Error", err)
+	}
+}
+
+func TestFaultMatch(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.InjectFaults(Fault{
+		Kind:  FaultServerError,
+		Match: func(r *http.Request) bool { return strings.HasSuffix(r.URL.Path, "/orders.json") },
+	})
+
+	client := server.NewClient(goshopify.WithRetry(goshopify.RetryPolicy{}))
+	ctx := context.Background()
+
+	_, err := client.Shop.Get(ctx, nil)
+	if err != nil {
+		t.Errorf("Shop.Get returned %v, expected no fault", err)
+	}
+
+	_, err = client.Order.List(ctx, nil)
+	if !errors.As(err, new(goshopify.ServerError)) {
+		t.Errorf("Order.List returned %#v, expected a ServerError", err)
+	}
+}
+
+func TestRandomFaults(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.NewClient(goshopify.WithRetry(goshopify.RetryPolicy{}))
+	ctx := context.Background()
+
+	server.InjectRandomFaults(1, 1, Fault{Kind: FaultServerError}, Fault{Kind: FaultRateLimit})
+	for i := 0; i < 5; i++ {
+		_, err := client.Shop.Get(ctx, nil)
+		if !errors.As(err, new(goshopify.ResponseError)) {
+			t.Errorf("Shop.Get returned %#v, expected a fault", err)
+		}
+	}
+
+	server.InjectRandomFaults(0.5, 1, Fault{Kind: FaultServerError})
+	failed := 0
+	for i := 0; i < 100; i++ {
+		_, err := client.Shop.Get(ctx, nil)
+		if err != nil {
+			failed++
+		}
+	}
+	if failed < 25 || failed > 75 {
+		t.Errorf("%d of 100 requests failed, expected about 50", failed)
+	}
+
+	server.ClearFaults()
+	_, err := client.Shop.Get(ctx, nil)
+	if err != nil {
+		t.Errorf("Shop.Get returned %v after clearing the faults", err)
+	}
+
+	if server.InjectedFaults() != 5+failed {
+		t.Errorf("Injected %d faults, expected %d", server.InjectedFaults(), 5+failed)
+	}
+}
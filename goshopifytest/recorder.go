@@ -0,0 +1,419 @@
+// Package goshopifytest provides helpers to test code that uses goshopify
+// without a Shopify store.
+package goshopifytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Mode determines whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette without sending any
+	// requests.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests through the underlying transport and records
+	// the interactions to the cassette.
+	ModeRecord
+)
+
+// Redacted replaces scrubbed secrets in cassettes.
+const Redacted = "REDACTED"
+
+// Headers, query parameters and JSON fields that carry secrets and are
+// scrubbed from cassettes. The OAuth ones are only scrubbed from OAuth
+// requests, since e.g. discount codes also have a "code" field.
+var (
+	scrubbedHeaders = []string{
+		"X-Shopify-Access-Token",
+		"X-Shopify-Hmac-Sha256",
+		"Authorization",
+		"Cookie",
+		"Set-Cookie",
+	}
+	scrubbedParams      = []string{"hmac", "signature", "access_token"}
+	scrubbedFields      = []string{"access_token", "client_secret"}
+	scrubbedOAuthParams = []string{"code"}
+	scrubbedOAuthFields = []string{"code"}
+)
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the request of an Interaction.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response of an Interaction.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is the file format in which a Recorder stores interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette from a file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := new(Cassette)
+	err = json.Unmarshal(data, cassette)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %v", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to a file.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Recorder is an http.RoundTripper that records interactions with Shopify to
+// a cassette file and replays them, so a run against a development store can
+// be turned into a deterministic test. Access tokens, HMACs and other secrets
+// are scrubbed before interactions are stored.
+//
+// In replay mode, a request is answered with the first interaction that was
+// not replayed yet and matches its method, path, query and body. Requests are
+// scrubbed before matching, so secrets do not have to be known to replay a
+// cassette.
+//
+//	recorder, err := goshopifytest.NewRecorder("testdata/orders.json", goshopifytest.ModeReplay)
+//	defer recorder.Stop()
+//	client := goshopify.NewClient(app, "shopname", "token", goshopify.WithHTTPClient(recorder.HTTPClient()))
+type Recorder struct {
+	// The transport used to send requests in record mode. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Scrub is called for every interaction after the built-in scrubbing,
+	// both before recording and, for the request only, before matching. It
+	// can be used to scrub additional data.
+	Scrub func(*Interaction)
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette *Cassette
+	replayed []bool
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In replay mode,
+// the cassette is loaded immediately. In record mode, the cassette is written
+// by Stop, replacing an existing one.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		mode:     mode,
+		path:     path,
+		cassette: new(Cassette),
+	}
+
+	if mode == ModeReplay {
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.replayed = make([]bool, len(cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// HTTPClient returns an HTTP client that uses the recorder as its transport.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop writes the cassette in record mode. It does nothing in replay mode.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// RoundTrip records or replays a request, depending on the mode.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: recordRequest(req, body),
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	}
+	r.scrub(&interaction)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	interaction := Interaction{Request: recordRequest(req, body)}
+	r.scrub(&interaction)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, recorded := range r.cassette.Interactions {
+		if r.replayed[i] || !matches(recorded.Request, interaction.Request) {
+			continue
+		}
+		r.replayed[i] = true
+
+		header := recorded.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.Response.StatusCode, http.StatusText(recorded.Response.StatusCode)),
+			StatusCode:    recorded.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Response.Body))),
+			ContentLength: int64(len(recorded.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("goshopifytest: no recorded interaction for %s %s in %s", req.Method, interaction.Request.URL, r.path)
+}
+
+// Reads the body of a request and replaces it, so it can be sent anyway.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func recordRequest(req *http.Request, body []byte) RecordedRequest {
+	return RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   string(body),
+	}
+}
+
+// Scrubs the secrets from an interaction.
+func (r *Recorder) scrub(interaction *Interaction) {
+	scrubHeader(interaction.Request.Header)
+	scrubHeader(interaction.Response.Header)
+
+	params, fields := scrubbedParams, scrubbedFields
+	if isOAuthRequest(interaction.Request.URL) {
+		params = append(append([]string(nil), params...), scrubbedOAuthParams...)
+		fields = append(append([]string(nil), fields...), scrubbedOAuthFields...)
+	}
+	interaction.Request.URL = scrubURL(interaction.Request.URL, params)
+	interaction.Request.Body = scrubBody(interaction.Request.Body, fields)
+	interaction.Response.Body = scrubBody(interaction.Response.Body, scrubbedFields)
+
+	if r.Scrub != nil {
+		r.Scrub(interaction)
+	}
+}
+
+func scrubHeader(h http.Header) {
+	for _, name := range scrubbedHeaders {
+		if h.Get(name) != "" {
+			h.Set(name, Redacted)
+		}
+	}
+}
+
+// Reports whether a request is part of the OAuth flow, e.g. the request for an
+// access token to admin/oauth/access_token.
+func isOAuthRequest(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && strings.Contains(u.Path, "/admin/oauth/")
+}
+
+func scrubURL(rawURL string, params []string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	if u.User != nil {
+		u.User = url.UserPassword(u.User.Username(), Redacted)
+	}
+
+	q := u.Query()
+	for _, param := range params {
+		if q.Get(param) != "" {
+			q.Set(param, Redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// Scrubs the given fields of a JSON body. Other bodies are returned as is.
+func scrubBody(body string, fields []string) string {
+	var v interface{}
+	if body == "" || json.Unmarshal([]byte(body), &v) != nil {
+		return body
+	}
+
+	if !scrubValue(v, fields) {
+		return body
+	}
+
+	scrubbed, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(scrubbed)
+}
+
+// Scrubs the given fields of a decoded JSON value in place and reports
+// whether any were found.
+func scrubValue(v interface{}, fields []string) bool {
+	found := false
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if contains(fields, key) {
+				v[key] = Redacted
+				found = true
+				continue
+			}
+			if scrubValue(value, fields) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if scrubValue(value, fields) {
+				found = true
+			}
+		}
+	}
+
+	return found
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether a request matches a recorded request on method, path,
+// query and body. The order of query parameters and the formatting of JSON
+// bodies do not matter.
+func matches(recorded, req RecordedRequest) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return false
+	}
+
+	if recordedURL.Path != reqURL.Path ||
+		!reflect.DeepEqual(recordedURL.Query(), reqURL.Query()) {
+		return false
+	}
+
+	return bodiesEqual(recorded.Body, req.Body)
+}
+
+func bodiesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
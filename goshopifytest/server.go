@@ -0,0 +1,730 @@
+package goshopifytest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+// A stored resource, as decoded from JSON.
+type object = map[string]interface{}
+
+// The REST resources served by a Server.
+type resource struct {
+	// The key that wraps a single resource in requests and responses.
+	singular string
+
+	// The fields that must not be blank.
+	required []string
+
+	// The resource the resource is nested under, e.g. products for variants.
+	// Nested resources are only listed and created under their parent.
+	parent string
+
+	// Whether the resource can be accessed without its parent by ID, e.g.
+	// admin/variants/1.json.
+	topLevel bool
+}
+
+var resources = map[string]resource{
+	"products":   {singular: "product", required: []string{"title"}, topLevel: true},
+	"variants":   {singular: "variant", parent: "products", topLevel: true},
+	"images":     {singular: "image", parent: "products"},
+	"orders":     {singular: "order", required: []string{"line_items"}, topLevel: true},
+	"customers":  {singular: "customer", topLevel: true},
+	"webhooks":   {singular: "webhook", required: []string{"topic", "address"}, topLevel: true},
+	"metafields": {singular: "metafield", required: []string{"namespace", "key", "value"}, topLevel: true},
+}
+
+// Fields that are set by the server and can't be changed by requests.
+var protectedFields = []string{"id", "created_at", "updated_at", "product_id", "owner_id", "owner_resource"}
+
+const (
+	defaultLimit = 50
+	maxLimit     = 250
+)
+
+// Server is an in-memory stand-in for the Shopify Admin REST API, for
+// integration tests without a development store. It serves the resources this
+// library supports (products with their variants and images, orders,
+// customers, webhooks, metafields and the shop) with create, update and
+// delete semantics, assigns IDs and timestamps, supports count.json, since_id,
+// ids, limit and cursor pagination, and returns 401, 404 and 422 errors in the
+// shapes Shopify uses. Requests must carry an access token or basic auth
+// credentials, which are not checked otherwise.
+//
+//	server := goshopifytest.NewServer()
+//	defer server.Close()
+//	client := server.NewClient()
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	nextID    int
+	requests  int
+	shop      object
+	resources map[string]map[int]object
+	faults    faultState
+}
+
+// NewServer starts and returns a new Server with an empty store. The caller
+// should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		nextID: 1,
+		shop: object{
+			"id":               1,
+			"name":             "Fake Shop",
+			"email":            "owner@fakeshop.example",
+			"domain":           "fakeshop.myshopify.com",
+			"myshopify_domain": "fakeshop.myshopify.com",
+			"currency":         "USD",
+			"timezone":         "(GMT+00:00) UTC",
+			"iana_timezone":    "UTC",
+		},
+		resources: make(map[string]map[int]object),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns a client for the server. The options are applied after
+// the ones that point the client at the server.
+func (s *Server) NewClient(opts ...goshopify.Option) *goshopify.Client {
+	opts = append([]goshopify.Option{
+		goshopify.WithBaseURL(s.URL),
+		goshopify.WithHTTPClient(s.Client()),
+	}, opts...)
+	return goshopify.NewClient(goshopify.App{}, "fakeshop", "token", opts...)
+}
+
+// SetShop replaces the shop served by shop.json with v, which is encoded as
+// JSON, e.g. a goshopify.Shop.
+func (s *Server) SetShop(v interface{}) {
+	shop := mustObject(v)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shop = shop
+}
+
+// Seed stores v, which is encoded as JSON, as a resource of the given kind,
+// e.g. "orders", without validating it, and returns its ID. The ID of v is
+// used if it is set. Nested resources such as variants must have the ID of
+// their parent set, e.g. in product_id. Seed panics if the kind is unknown or
+// v does not encode to a JSON object.
+func (s *Server) Seed(kind string, v interface{}) int {
+	if _, ok := resources[kind]; !ok {
+		panic(fmt.Sprintf("goshopifytest: unknown resource %q", kind))
+	}
+	o := mustObject(v)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, _ := toInt(o["id"])
+	if id >= s.nextID {
+		s.nextID = id + 1
+	}
+	return s.insert(kind, o, id)
+}
+
+// Get decodes the stored resource of the given kind and ID into v, as it would
+// be served. It reports whether the resource exists.
+func (s *Server) Get(kind string, id int, v interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.resources[kind][id]
+	if !ok {
+		return false
+	}
+
+	data, err := json.Marshal(s.render(kind, o))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// Count returns the number of stored resources of the given kind.
+func (s *Server) Count(kind string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.resources[kind])
+}
+
+// Encodes v as JSON and decodes it into an object.
+func mustObject(v interface{}) object {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("goshopifytest: %v", err))
+	}
+
+	var o object
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&o)
+	if err != nil {
+		panic(fmt.Sprintf("goshopifytest: %T is not a JSON object: %v", v, err))
+	}
+	return o
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	w.Header().Set("X-Request-Id", fmt.Sprintf("fake-%d", s.requests))
+	fault, ok := s.nextFault(r)
+	s.mu.Unlock()
+
+	if ok && s.injectFault(w, r, fault) {
+		return
+	}
+
+	s.serve(w, r)
+}
+
+// Serves a request from the store.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	_, _, hasBasicAuth := r.BasicAuth()
+	if r.Header.Get("X-Shopify-Access-Token") == "" && !hasBasicAuth {
+		writeJSON(w, http.StatusUnauthorized, object{
+			"errors": "[API] Invalid API key or access token (unrecognized login or wrong password)",
+		})
+		return
+	}
+
+	segments, version, ok := parsePath(r.URL.Path)
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if version != "" {
+		w.Header().Set(goshopify.APIVersionHeader, version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.route(w, r, segments)
+}
+
+// Splits an admin path such as /admin/api/2024-10/products/1.json into its
+// segments and the API version.
+func parsePath(path string) (segments []string, version string, ok bool) {
+	segments = strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "admin" {
+		return nil, "", false
+	}
+	segments = segments[1:]
+
+	if segments[0] == "api" {
+		if len(segments) < 3 {
+			return nil, "", false
+		}
+		version = segments[1]
+		segments = segments[2:]
+	}
+
+	last := len(segments) - 1
+	if !strings.HasSuffix(segments[last], ".json") {
+		return nil, "", false
+	}
+	segments[last] = strings.TrimSuffix(segments[last], ".json")
+
+	return segments, version, true
+}
+
+// A set of fields that the resources in a collection share, e.g. the
+// product_id of the variants of a product.
+type scope map[string]interface{}
+
+func (sc scope) matches(o object) bool {
+	for k, v := range sc {
+		if fmt.Sprint(o[k]) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 1 && segments[0] == "shop" {
+		if r.Method != "GET" {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, object{"shop": s.shop})
+		return
+	}
+
+	kind := segments[0]
+	res, ok := resources[kind]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	// Top-level collections, e.g. products.json and products/count.json.
+	// Top-level metafields belong to the shop.
+	sc := scope{}
+	if kind == "metafields" {
+		sc = scope{"owner_resource": "shop", "owner_id": s.shop["id"]}
+	}
+
+	// Nested collections, e.g. products/1/variants.json or
+	// products/1/metafields.json.
+	if len(segments) >= 3 {
+		parentID, err := strconv.Atoi(segments[1])
+		child, childOK := resources[segments[2]]
+		if err != nil || !childOK || (child.parent != kind && segments[2] != "metafields") {
+			writeNotFound(w)
+			return
+		}
+		if _, ok := s.resources[kind][parentID]; !ok {
+			writeNotFound(w)
+			return
+		}
+
+		if segments[2] == "metafields" {
+			sc = scope{"owner_resource": res.singular, "owner_id": parentID}
+		} else {
+			sc = scope{res.singular + "_id": parentID}
+		}
+		kind, res = segments[2], child
+		segments = segments[2:]
+	} else if res.parent != "" && len(segments) == 1 {
+		// Nested resources can't be listed or created without their parent.
+		writeNotFound(w)
+		return
+	} else if res.parent != "" && !res.topLevel {
+		writeNotFound(w)
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == "GET":
+		s.list(w, r, kind, sc)
+	case len(segments) == 1 && r.Method == "POST":
+		s.create(w, r, kind, sc)
+	case len(segments) == 2 && segments[1] == "count" && r.Method == "GET":
+		s.count(w, r, kind, sc)
+	case len(segments) == 2:
+		id, err := strconv.Atoi(segments[1])
+		o, ok := s.resources[kind][id]
+		if err != nil || !ok || !sc.matches(o) {
+			writeNotFound(w)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			writeJSON(w, http.StatusOK, object{res.singular: s.render(kind, o)})
+		case "PUT":
+			s.update(w, r, kind, o)
+		case "DELETE":
+			s.delete(kind, id)
+			writeJSON(w, http.StatusOK, object{})
+		default:
+			writeNotFound(w)
+		}
+	default:
+		writeNotFound(w)
+	}
+}
+
+// Returns the resources of a collection that match the scope and the
+// since_id and ids filters of the query, ordered by ID.
+func (s *Server) filter(kind string, sc scope, query url.Values) ([]object, error) {
+	sinceID := 0
+	if v := query.Get("since_id"); v != "" {
+		var err error
+		sinceID, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("since_id")
+		}
+	}
+
+	var ids map[int]bool
+	if v := query.Get("ids"); v != "" {
+		ids = make(map[int]bool)
+		for _, part := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("ids")
+			}
+			ids[id] = true
+		}
+	}
+
+	var matched []object
+	for _, id := range s.sortedIDs(kind) {
+		o := s.resources[kind][id]
+		if id <= sinceID || (ids != nil && !ids[id]) || !sc.matches(o) {
+			continue
+		}
+		matched = append(matched, o)
+	}
+	return matched, nil
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, kind string, sc scope) {
+	query := r.URL.Query()
+
+	limit := defaultLimit
+	if v := query.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			writeJSON(w, http.StatusBadRequest, object{"errors": object{"limit": "Invalid value"}})
+			return
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+	}
+
+	// The cursor of a page is the ID of the last resource of the previous
+	// page, and replaces all other filters but the limit.
+	if pageInfo := query.Get("page_info"); pageInfo != "" {
+		sinceID, err := base64.RawURLEncoding.DecodeString(pageInfo)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, object{"errors": object{"page_info": "Invalid value"}})
+			return
+		}
+		query = url.Values{"since_id": {string(sinceID)}}
+	}
+
+	matched, err := s.filter(kind, sc, query)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, object{"errors": object{err.Error(): "Invalid value"}})
+		return
+	}
+
+	if len(matched) > limit {
+		matched = matched[:limit]
+
+		next := *r.URL
+		next.Scheme = "http"
+		next.Host = r.Host
+		last, _ := toInt(matched[len(matched)-1]["id"])
+		next.RawQuery = url.Values{
+			"limit":     {strconv.Itoa(limit)},
+			"page_info": {base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(last)))},
+		}.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+	}
+
+	rendered := make([]object, len(matched))
+	for i, o := range matched {
+		rendered[i] = s.render(kind, o)
+	}
+	writeJSON(w, http.StatusOK, object{kind: rendered})
+}
+
+func (s *Server) count(w http.ResponseWriter, r *http.Request, kind string, sc scope) {
+	matched, err := s.filter(kind, sc, r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, object{"errors": object{err.Error(): "Invalid value"}})
+		return
+	}
+	writeJSON(w, http.StatusOK, object{"count": len(matched)})
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, kind string, sc scope) {
+	o, ok := decodeBody(w, r, resources[kind].singular)
+	if !ok {
+		return
+	}
+
+	for _, field := range protectedFields {
+		delete(o, field)
+	}
+	for k, v := range sc {
+		o[k] = v
+	}
+
+	if !s.validate(w, kind, o, 0) {
+		return
+	}
+
+	id := s.insert(kind, o, 0)
+	writeJSON(w, http.StatusCreated, object{resources[kind].singular: s.render(kind, s.resources[kind][id])})
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, kind string, existing object) {
+	changes, ok := decodeBody(w, r, resources[kind].singular)
+	if !ok {
+		return
+	}
+
+	for _, field := range protectedFields {
+		delete(changes, field)
+	}
+
+	updated := make(object, len(existing)+len(changes))
+	for k, v := range existing {
+		updated[k] = v
+	}
+	for k, v := range changes {
+		updated[k] = v
+	}
+
+	id, _ := toInt(existing["id"])
+	if !s.validate(w, kind, updated, id) {
+		return
+	}
+
+	updated["updated_at"] = now()
+	s.store(kind, id, updated)
+	writeJSON(w, http.StatusOK, object{resources[kind].singular: s.render(kind, s.resources[kind][id])})
+}
+
+// Decodes the resource wrapped in the given key from the request body, with
+// null fields left out. A 400 error is written if there is none.
+func decodeBody(w http.ResponseWriter, r *http.Request, key string) (object, bool) {
+	var body map[string]object
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	err := decoder.Decode(&body)
+	if err != nil || body[key] == nil {
+		writeJSON(w, http.StatusBadRequest, object{"errors": object{key: "Required parameter missing or invalid"}})
+		return nil, false
+	}
+
+	o := body[key]
+	for k, v := range o {
+		if v == nil {
+			delete(o, k)
+		}
+	}
+	return o, true
+}
+
+// Writes a 422 error and returns false if the resource is invalid. The ID is
+// zero for new resources.
+func (s *Server) validate(w http.ResponseWriter, kind string, o object, id int) bool {
+	errors := make(map[string][]string)
+
+	for _, field := range resources[kind].required {
+		if isBlank(o[field]) {
+			errors[field] = append(errors[field], "can't be blank")
+		}
+	}
+
+	if kind == "webhooks" {
+		for otherID, other := range s.resources[kind] {
+			if otherID != id && other["topic"] == o["topic"] && other["address"] == o["address"] {
+				errors["address"] = append(errors["address"], "for this topic has already been taken")
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, object{"errors": errors})
+		return false
+	}
+	return true
+}
+
+func isBlank(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// Stores a new resource and returns its ID. A new ID is assigned if id is
+// zero. Variants and images of products are stored separately.
+func (s *Server) insert(kind string, o object, id int) int {
+	if id == 0 {
+		id = s.nextID
+		s.nextID++
+	}
+
+	o["id"] = id
+	if o["created_at"] == nil {
+		o["created_at"] = now()
+	}
+	if o["updated_at"] == nil {
+		o["updated_at"] = o["created_at"]
+	}
+
+	switch kind {
+	case "products":
+		if o["variants"] == nil {
+			o["variants"] = []interface{}{object{"title": "Default Title", "price": "0.00"}}
+		}
+	case "webhooks":
+		if isBlank(o["format"]) {
+			o["format"] = "json"
+		}
+	case "metafields":
+		if o["owner_resource"] == nil {
+			o["owner_resource"] = "shop"
+			o["owner_id"] = s.shop["id"]
+		}
+	}
+
+	s.store(kind, id, o)
+	return id
+}
+
+// Stores a resource under its ID. The variants and images of a product replace
+// its existing ones.
+func (s *Server) store(kind string, id int, o object) {
+	if kind == "products" {
+		for _, child := range []string{"variants", "images"} {
+			if nested, ok := o[child].([]interface{}); ok {
+				s.replaceChildren(child, id, nested)
+			}
+			delete(o, child)
+		}
+		delete(o, "image")
+	}
+
+	if s.resources[kind] == nil {
+		s.resources[kind] = make(map[int]object)
+	}
+	s.resources[kind][id] = o
+}
+
+// Replaces the variants or images of a product. Nested resources with the ID
+// of an existing one update it, the others are created.
+func (s *Server) replaceChildren(kind string, productID int, nested []interface{}) {
+	keep := make(map[int]bool)
+
+	for i, v := range nested {
+		o, ok := v.(object)
+		if !ok {
+			continue
+		}
+
+		id, _ := toInt(o["id"])
+		existing, exists := s.resources[kind][id]
+		if !exists || !(scope{"product_id": productID}).matches(existing) {
+			child := make(object, len(o))
+			for k, v := range o {
+				child[k] = v
+			}
+			delete(child, "id")
+			child["product_id"] = productID
+			if child["position"] == nil {
+				child["position"] = i + 1
+			}
+			keep[s.insert(kind, child, 0)] = true
+			continue
+		}
+
+		for k, v := range o {
+			if v != nil && k != "id" && k != "product_id" && k != "created_at" {
+				existing[k] = v
+			}
+		}
+		existing["updated_at"] = now()
+		keep[id] = true
+	}
+
+	for id, o := range s.resources[kind] {
+		if !keep[id] && (scope{"product_id": productID}).matches(o) {
+			delete(s.resources[kind], id)
+		}
+	}
+}
+
+// Deletes a resource along with the resources nested under it and its
+// metafields.
+func (s *Server) delete(kind string, id int) {
+	delete(s.resources[kind], id)
+
+	owner := resources[kind].singular
+	for childKind, child := range resources {
+		for childID, o := range s.resources[childKind] {
+			nested := child.parent == kind && (scope{owner + "_id": id}).matches(o)
+			owned := childKind == "metafields" && (scope{"owner_resource": owner, "owner_id": id}).matches(o)
+			if nested || owned {
+				delete(s.resources[childKind], childID)
+			}
+		}
+	}
+}
+
+// Returns the representation of a resource, with the variants and images of
+// products embedded.
+func (s *Server) render(kind string, o object) object {
+	rendered := make(object, len(o)+3)
+	for k, v := range o {
+		rendered[k] = v
+	}
+
+	if kind == "products" {
+		id, _ := toInt(o["id"])
+		for _, child := range []string{"variants", "images"} {
+			nested := []object{}
+			for _, childID := range s.sortedIDs(child) {
+				c := s.resources[child][childID]
+				if (scope{"product_id": id}).matches(c) {
+					nested = append(nested, c)
+				}
+			}
+			rendered[child] = nested
+			if child == "images" && len(nested) > 0 {
+				rendered["image"] = nested[0]
+			}
+		}
+	}
+
+	return rendered
+}
+
+func (s *Server) sortedIDs(kind string) []int {
+	ids := make([]int, 0, len(s.resources[kind]))
+	for id := range s.resources[kind] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case json.Number:
+		i, err := v.Int64()
+		return int(i), err == nil
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, object{"errors": "Not Found"})
+}
@@ -0,0 +1,200 @@
+package goshopifytest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+func TestServerProducts(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.NewClient()
+	ctx := context.Background()
+
+	_, err := client.Product.Create(ctx, &goshopify.Product{})
+	var validationErr goshopify.ValidationError
+	if !errors.As(err, &validationErr) ||
+		!reflect.DeepEqual(validationErr.FieldErrors, map[string][]string{"title": {"can't be blank"}}) {
+		t.Errorf("Product.Create returned %#v, expected a ValidationError for the title", err)
+	}
+
+	product, err := client.Product.Create(ctx, &goshopify.Product{
+		Title:    "Snowboard",
+		Variants: []goshopify.Variant{{Title: "Small", Sku: "S"}, {Title: "Large", Sku: "L"}},
+	})
+	if err != nil {
+		t.Fatalf("Product.Create returned error: %v", err)
+	}
+	if product.ID == 0 || product.CreatedAt == nil || len(product.Variants) != 2 ||
+		product.Variants[0].ProductID != product.ID || product.Variants[0].ID == 0 {
+		t.Errorf("Product.Create returned %+v", product)
+	}
+
+	variant, err := client.Variant.Create(ctx, product.ID, &goshopify.Variant{Title: "Medium", Sku: "M"})
+	if err != nil {
+		t.Fatalf("Variant.Create returned error: %v", err)
+	}
+
+	count, err := client.Variant.Count(ctx, product.ID, nil)
+	if err != nil || count != 3 {
+		t.Errorf("Variant.Count returned %d, %v, expected 3", count, err)
+	}
+
+	variant.Sku = "MEDIUM"
+	variant, err = client.Variant.Update(ctx, variant)
+	if err != nil || variant.Sku != "MEDIUM" || variant.ProductID != product.ID {
+		t.Errorf("Variant.Update returned %+v, %v", variant, err)
+	}
+
+	product.Title = "Big snowboard"
+	product.Variants = nil
+	product, err = client.Product.Update(ctx, product)
+	if err != nil || product.Title != "Big snowboard" || len(product.Variants) != 3 {
+		t.Errorf("Product.Update returned %+v, %v", product, err)
+	}
+
+	_, err = client.Image.Create(ctx, product.ID, &goshopify.Image{Src: "https://example.com/snowboard.png"})
+	if err != nil {
+		t.Fatalf("Image.Create returned error: %v", err)
+	}
+
+	product, err = client.Product.Get(ctx, product.ID, nil)
+	if err != nil || len(product.Images) != 1 || product.Image.Src != "https://example.com/snowboard.png" {
+		t.Errorf("Product.Get returned %+v, %v", product, err)
+	}
+
+	err = client.Product.Delete(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("Product.Delete returned error: %v", err)
+	}
+
+	_, err = client.Product.Get(ctx, product.ID, nil)
+	if !errors.As(err, new(goshopify.NotFoundError)) {
+		t.Errorf("Product.Get returned %#v, expected a NotFoundError", err)
+	}
+
+	_, err = client.Variant.Get(ctx, variant.ID, nil)
+	if !errors.As(err, new(goshopify.NotFoundError)) {
+		t.Errorf("Variant.Get returned %#v, expected the variant to be deleted with its product", err)
+	}
+
+	if server.Count("products") != 0 || server.Count("variants") != 0 || server.Count("images") != 0 {
+		t.Errorf("Server still stores %d products, %d variants and %d images",
+			server.Count("products"), server.Count("variants"), server.Count("images"))
+	}
+}
+
+func TestServerListing(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	for i := 0; i < 7; i++ {
+		server.Seed("orders", goshopify.Order{Email: "order@example.com"})
+	}
+	server.Seed("orders", goshopify.Order{ID: 100})
+
+	client := server.NewClient()
+	ctx := context.Background()
+
+	count, err := client.Order.Count(ctx, nil)
+	if err != nil || count != 8 {
+		t.Errorf("Order.Count returned %d, %v, expected 8", count, err)
+	}
+
+	orders, err := client.Order.List(ctx, goshopify.OrderListOptions{SinceID: 3, Limit: 2})
+	if err != nil || len(orders) != 2 || orders[0].ID != 4 || orders[1].ID != 5 {
+		t.Errorf("Order.List returned %+v, %v, expected orders 4 and 5", orders, err)
+	}
+
+	all, err := client.Order.ListAll(ctx, goshopify.OrderListOptions{Limit: 3})
+	if err != nil || len(all) != 8 || all[7].ID != 100 {
+		t.Errorf("Order.ListAll returned %d orders, %v, expected 8", len(all), err)
+	}
+
+	var order goshopify.Order
+	if !server.Get("orders", 100, &order) || order.ID != 100 {
+		t.Errorf("Server.Get returned %+v", order)
+	}
+}
+
+func TestServerWebhooks(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.NewClient()
+	ctx := context.Background()
+
+	webhook := goshopify.Webhook{Topic: "orders/create", Address: "https://example.com/webhooks"}
+	created, err := client.Webhook.Create(ctx, webhook)
+	if err != nil || created.ID == 0 || created.Format != "json" {
+		t.Errorf("Webhook.Create returned %+v, %v", created, err)
+	}
+
+	_, err = client.Webhook.Create(ctx, webhook)
+	var validationErr goshopify.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.FieldErrors["address"]) != 1 {
+		t.Errorf("Webhook.Create returned %#v, expected the address to be taken", err)
+	}
+
+	_, err = client.Webhook.Create(ctx, goshopify.Webhook{Topic: string(goshopify.TopicOrdersPaid)})
+	expected := map[string][]string{"address": {"can't be blank"}}
+	if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.FieldErrors, expected) {
+		t.Errorf("Webhook.Create returned %#v, expected blank address", err)
+	}
+}
+
+func TestServerMetafields(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	productID := server.Seed("products", goshopify.Product{Title: "Snowboard"})
+	server.Seed("metafields", goshopify.Metafield{Namespace: "shop", Key: "k", Value: "v"})
+
+	client := server.NewClient()
+	ctx := context.Background()
+
+	// Metafields of a product are created through the generic Post.
+	err := client.Post(ctx, "admin/products/1/metafields.json",
+		goshopify.MetafieldResource{Metafield: &goshopify.Metafield{Namespace: "product", Key: "k", Value: "v", ValueType: "string"}}, nil)
+	if err != nil {
+		t.Fatalf("Post returned error: %v", err)
+	}
+
+	metafields, err := client.Metafield.ListForObject(ctx, "products/1", nil)
+	if err != nil || len(metafields) != 1 || metafields[0].OwnerID != productID || metafields[0].OwnerResource != "product" {
+		t.Errorf("Metafield.ListForObject returned %+v, %v", metafields, err)
+	}
+
+	metafields, err = client.Metafield.List(ctx, nil)
+	if err != nil || len(metafields) != 1 || metafields[0].Namespace != "shop" {
+		t.Errorf("Metafield.List returned %+v, %v, expected the shop metafield", metafields, err)
+	}
+}
+
+func TestServerShop(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.SetShop(goshopify.Shop{ID: 7, Name: "Snowboards"})
+
+	shop, err := server.NewClient().Shop.Get(context.Background(), nil)
+	if err != nil || shop.ID != 7 || shop.Name != "Snowboards" {
+		t.Errorf("Shop.Get returned %+v, %v", shop, err)
+	}
+}
+
+func TestServerUnauthorized(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := goshopify.NewClient(goshopify.App{}, "fakeshop", "", goshopify.WithBaseURL(server.URL))
+	_, err := client.Shop.Get(context.Background(), nil)
+	if !errors.As(err, new(goshopify.UnauthorizedError)) {
+		t.Errorf("Shop.Get returned %#v, expected an UnauthorizedError", err)
+	}
+}
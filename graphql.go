@@ -0,0 +1,268 @@
+package goshopify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const graphQLPath = "admin/graphql.json"
+
+// GraphQLService is an interface for interfacing with the GraphQL Admin API.
+// See: https://shopify.dev/docs/api/admin-graphql
+type GraphQLService interface {
+	Query(ctx context.Context, query string, variables, resp interface{}) error
+	QueryWithCost(ctx context.Context, query string, variables, resp interface{}) (*GraphQLCost, error)
+}
+
+// GraphQLServiceOp handles communication with the GraphQL endpoint of the
+// Shopify API.
+type GraphQLServiceOp struct {
+	client *Client
+}
+
+// The body of a GraphQL request.
+type graphQLRequest struct {
+	Query     string      `json:"query"`
+	Variables interface{} `json:"variables,omitempty"`
+}
+
+// The body of a GraphQL response.
+type graphQLResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     GraphQLErrors   `json:"errors"`
+	Extensions struct {
+		Cost *GraphQLCost `json:"cost"`
+	} `json:"extensions"`
+}
+
+// GraphQLCost is the cost of a GraphQL query as reported in the extensions of
+// the response.
+// See: https://shopify.dev/docs/api/usage/rate-limits#graphql-admin-api-rate-limits
+type GraphQLCost struct {
+	RequestedQueryCost int                   `json:"requestedQueryCost"`
+	ActualQueryCost    int                   `json:"actualQueryCost"`
+	ThrottleStatus     GraphQLThrottleStatus `json:"throttleStatus"`
+}
+
+// GraphQLThrottleStatus is the state of a shop's bucket of GraphQL query
+// points. Queries are throttled when their cost exceeds the currently
+// available points, which are restored at a fixed rate per second.
+type GraphQLThrottleStatus struct {
+	MaximumAvailable   float64 `json:"maximumAvailable"`
+	CurrentlyAvailable float64 `json:"currentlyAvailable"`
+	RestoreRate        float64 `json:"restoreRate"`
+}
+
+// GraphQLErrorLocation is the position in the query a GraphQLError refers to.
+type GraphQLErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError represents an entry of the top-level "errors" of a GraphQL
+// response, e.g. a syntax error in the query or a denied access scope.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLErrorLocation `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// Code returns the error code in the extensions of the error, e.g.
+// "THROTTLED" or "ACCESS_DENIED", if any.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLErrors is the error returned for a GraphQL response that contains
+// top-level errors.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, ", ")
+}
+
+// UserError represents an entry of the "userErrors" that mutations return when
+// the input was invalid.
+type UserError struct {
+	Field   []string `json:"field"`
+	Message string   `json:"message"`
+	Code    string   `json:"code,omitempty"`
+}
+
+func (e UserError) Error() string {
+	if len(e.Field) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(e.Field, "."), e.Message)
+}
+
+// UserErrors is the error returned for a GraphQL response that contains
+// non-empty "userErrors" anywhere in its data.
+type UserErrors []UserError
+
+func (e UserErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, ", ")
+}
+
+// Query posts a GraphQL query or mutation with the given variables and decodes
+// the "data" of the response into resp. Top-level errors are returned as
+// GraphQLErrors and user errors of mutations as UserErrors. The data is
+// decoded into resp in both cases, since it may be partially filled.
+func (s *GraphQLServiceOp) Query(ctx context.Context, query string, variables, resp interface{}) error {
+	_, err := s.QueryWithCost(ctx, query, variables, resp)
+	return err
+}
+
+// QueryWithCost is the same as Query, but also returns the cost of the query.
+// The cost is nil if the response did not include it.
+//
+// If the client's RateLimiter throttles GraphQL, the query is held back until
+// enough points are available and throttled queries are retried according to
+// the client's RetryPolicy.
+func (s *GraphQLServiceOp) QueryWithCost(ctx context.Context, query string, variables, resp interface{}) (*GraphQLCost, error) {
+	limiter := s.client.RateLimiter
+	shopName := s.client.baseURL.Host
+	data := graphQLRequest{Query: query, Variables: variables}
+
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			err := limiter.waitGraphQL(ctx, shopName, query)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resource := new(graphQLResponse)
+		err := s.client.Post(ctx, graphQLPath, data, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		cost := resource.Extensions.Cost
+		if limiter != nil && cost != nil {
+			limiter.updateGraphQL(shopName, query, cost)
+		}
+
+		err = decodeGraphQLResponse(resource, resp)
+		if isThrottled(err) && limiter != nil && limiter.ThrottleGraphQL && attempt < s.client.Retry.MaxAttempts {
+			continue
+		}
+
+		return cost, err
+	}
+}
+
+// Reports whether the error is a GraphQL error for a throttled query.
+func isThrottled(err error) bool {
+	errs, ok := err.(GraphQLErrors)
+	if !ok {
+		return false
+	}
+	for _, e := range errs {
+		if e.Code() == "THROTTLED" {
+			return true
+		}
+	}
+	return false
+}
+
+// Decodes the data of a GraphQL response into resp and returns the errors of
+// the response, if any.
+func decodeGraphQLResponse(r *graphQLResponse, resp interface{}) error {
+	hasData := len(r.Data) > 0 && string(r.Data) != "null"
+
+	if resp != nil && hasData {
+		err := json.Unmarshal(r.Data, resp)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(r.Errors) > 0 {
+		return r.Errors
+	}
+
+	if !hasData {
+		return nil
+	}
+
+	var generic interface{}
+	err := json.Unmarshal(r.Data, &generic)
+	if err != nil {
+		return err
+	}
+
+	userErrors, err := findUserErrors(generic)
+	if err != nil {
+		return err
+	}
+	if len(userErrors) > 0 {
+		return userErrors
+	}
+
+	return nil
+}
+
+// Collects all entries of "userErrors" fields anywhere in the decoded data.
+func findUserErrors(v interface{}) (UserErrors, error) {
+	var found UserErrors
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "userErrors" {
+				if _, ok := value.([]interface{}); ok {
+					// Round-trip the generic value through JSON to decode it
+					// into the typed errors.
+					js, err := json.Marshal(value)
+					if err != nil {
+						return nil, err
+					}
+
+					var userErrors UserErrors
+					err = json.Unmarshal(js, &userErrors)
+					if err != nil {
+						return nil, err
+					}
+
+					found = append(found, userErrors...)
+					continue
+				}
+			}
+
+			nested, err := findUserErrors(value)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, nested...)
+		}
+	case []interface{}:
+		for _, value := range v {
+			nested, err := findUserErrors(value)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, nested...)
+		}
+	}
+
+	return found, nil
+}
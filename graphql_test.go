@@ -0,0 +1,273 @@
+package goshopify
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+type graphQLShopResponse struct {
+	Shop struct {
+		Name string `json:"name"`
+	} `json:"shop"`
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var body graphQLRequest
+	var token string
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			token = req.Header.Get("X-Shopify-Access-Token")
+			js, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(js, &body)
+			return httpmock.NewStringResponse(200, `{"data": {"shop": {"name": "Foo Shop"}}}`), nil
+		})
+
+	resp := new(graphQLShopResponse)
+	query := `query($id: ID!) { shop { name } }`
+	err := client.GraphQL.Query(context.Background(), query, map[string]interface{}{"id": "1"}, resp)
+	if err != nil {
+		t.Fatalf("GraphQL.Query returned error: %v", err)
+	}
+
+	if resp.Shop.Name != "Foo Shop" {
+		t.Errorf("GraphQL.Query decoded name %q, expected %q", resp.Shop.Name, "Foo Shop")
+	}
+
+	if body.Query != query {
+		t.Errorf("GraphQL.Query sent query %q, expected %q", body.Query, query)
+	}
+
+	expectedVariables := map[string]interface{}{"id": "1"}
+	if !reflect.DeepEqual(body.Variables, expectedVariables) {
+		t.Errorf("GraphQL.Query sent variables %v, expected %v", body.Variables, expectedVariables)
+	}
+
+	if token != "abcd" {
+		t.Errorf("GraphQL.Query sent X-Shopify-Access-Token %q, expected %q", token, "abcd")
+	}
+}
+
+func TestGraphQLQueryErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		httpmock.NewStringResponder(200, `{
+			"data": null,
+			"errors": [{
+				"message": "Access denied for shop field.",
+				"locations": [{"line": 1, "column": 3}],
+				"path": ["shop"],
+				"extensions": {"code": "ACCESS_DENIED"}
+			}]
+		}`))
+
+	err := client.GraphQL.Query(context.Background(), `{ shop { name } }`, nil, new(graphQLShopResponse))
+
+	errs, ok := err.(GraphQLErrors)
+	if !ok {
+		t.Fatalf("GraphQL.Query returned %#v, expected GraphQLErrors", err)
+	}
+
+	expected := GraphQLErrors{{
+		Message:    "Access denied for shop field.",
+		Locations:  []GraphQLErrorLocation{{Line: 1, Column: 3}},
+		Path:       []interface{}{"shop"},
+		Extensions: map[string]interface{}{"code": "ACCESS_DENIED"},
+	}}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("GraphQL.Query returned %#v, expected %#v", errs, expected)
+	}
+
+	if errs[0].Code() != "ACCESS_DENIED" {
+		t.Errorf("GraphQLError.Code() = %q, expected %q", errs[0].Code(), "ACCESS_DENIED")
+	}
+}
+
+func TestGraphQLQueryUserErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		httpmock.NewStringResponder(200, `{
+			"data": {
+				"productCreate": {
+					"product": null,
+					"userErrors": [
+						{"field": ["input", "title"], "message": "Title can't be blank"}
+					]
+				}
+			}
+		}`))
+
+	resp := struct {
+		ProductCreate struct {
+			Product    *struct{ ID string } `json:"product"`
+			UserErrors []UserError          `json:"userErrors"`
+		} `json:"productCreate"`
+	}{}
+
+	err := client.GraphQL.Query(context.Background(), `mutation { productCreate(input: {}) { userErrors { field message } } }`, nil, &resp)
+
+	expected := UserErrors{{Field: []string{"input", "title"}, Message: "Title can't be blank"}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GraphQL.Query returned %#v, expected %#v", err, expected)
+	}
+
+	if err.Error() != "input.title: Title can't be blank" {
+		t.Errorf("UserErrors.Error() = %q", err.Error())
+	}
+
+	// The data is still decoded
+	if len(resp.ProductCreate.UserErrors) != 1 {
+		t.Errorf("GraphQL.Query decoded %+v, expected user errors", resp)
+	}
+}
+
+func TestGraphQLQueryEmptyUserErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		httpmock.NewStringResponder(200, `{"data": {"productCreate": {"product": {"id": "1"}, "userErrors": []}}}`))
+
+	err := client.GraphQL.Query(context.Background(), `mutation { productCreate(input: {}) { userErrors { field message } } }`, nil, nil)
+	if err != nil {
+		t.Errorf("GraphQL.Query returned error: %v", err)
+	}
+}
+
+func TestGraphQLQueryResponseError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		httpmock.NewStringResponder(401, `{"errors": "[API] Invalid API key or access token"}`))
+
+	err := client.GraphQL.Query(context.Background(), `{ shop { name } }`, nil, nil)
+	expected := UnauthorizedError{ResponseError{
+		Status:  401,
+		Message: "[API] Invalid API key or access token",
+		Body:    []byte(`{"errors": "[API] Invalid API key or access token"}`),
+	}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GraphQL.Query returned %#v, expected %#v", err, expected)
+	}
+}
+
+func graphQLCostBody(data string, requested, available int, errors string) string {
+	return `{
+		"data": ` + data + `,
+		` + errors + `
+		"extensions": {
+			"cost": {
+				"requestedQueryCost": ` + strconv.Itoa(requested) + `,
+				"actualQueryCost": ` + strconv.Itoa(requested) + `,
+				"throttleStatus": {
+					"maximumAvailable": 1000.0,
+					"currentlyAvailable": ` + strconv.Itoa(available) + `,
+					"restoreRate": 1000.0
+				}
+			}
+		}
+	}`
+}
+
+func TestGraphQLQueryWithCost(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		httpmock.NewStringResponder(200, graphQLCostBody(`{"shop": {"name": "Foo Shop"}}`, 12, 988, "")))
+
+	resp := new(graphQLShopResponse)
+	cost, err := client.GraphQL.QueryWithCost(context.Background(), `{ shop { name } }`, nil, resp)
+	if err != nil {
+		t.Fatalf("GraphQL.QueryWithCost returned error: %v", err)
+	}
+
+	expected := &GraphQLCost{
+		RequestedQueryCost: 12,
+		ActualQueryCost:    12,
+		ThrottleStatus: GraphQLThrottleStatus{
+			MaximumAvailable:   1000,
+			CurrentlyAvailable: 988,
+			RestoreRate:        1000,
+		},
+	}
+	if !reflect.DeepEqual(cost, expected) {
+		t.Errorf("GraphQL.QueryWithCost returned %+v, expected %+v", cost, expected)
+	}
+
+	status := client.GraphQLThrottleStatus()
+	if status.MaximumAvailable != 1000 || status.CurrentlyAvailable < 988 {
+		t.Errorf("Client.GraphQLThrottleStatus() = %+v, expected at least 988 of 1000 points", status)
+	}
+}
+
+func TestGraphQLQueryThrottled(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RateLimiter.ThrottleGraphQL = true
+
+	throttled := graphQLCostBody(`null`, 100, 0,
+		`"errors": [{"message": "Throttled", "extensions": {"code": "THROTTLED"}}],`)
+	ok := graphQLCostBody(`{"shop": {"name": "Foo Shop"}}`, 100, 900, "")
+
+	calls := 0
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		sequenceResponder(&calls,
+			httpmock.NewStringResponder(200, throttled),
+			httpmock.NewStringResponder(200, ok)))
+
+	// The query is retried once 100 points are restored, at 1000 points per
+	// second.
+	start := time.Now()
+	resp := new(graphQLShopResponse)
+	err := client.GraphQL.Query(context.Background(), `{ shop { name } }`, nil, resp)
+	if err != nil {
+		t.Fatalf("GraphQL.Query returned error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("GraphQL.Query made %d calls, expected 2", calls)
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("GraphQL.Query retried after %v, expected at least 90ms", elapsed)
+	}
+
+	if resp.Shop.Name != "Foo Shop" {
+		t.Errorf("GraphQL.Query decoded name %q, expected %q", resp.Shop.Name, "Foo Shop")
+	}
+}
+
+func TestGraphQLQueryThrottledWithoutThrottling(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/graphql.json",
+		sequenceResponder(&calls, httpmock.NewStringResponder(200, graphQLCostBody(`null`, 100, 0,
+			`"errors": [{"message": "Throttled", "extensions": {"code": "THROTTLED"}}],`))))
+
+	err := client.GraphQL.Query(context.Background(), `{ shop { name } }`, nil, nil)
+	if !isThrottled(err) {
+		t.Errorf("GraphQL.Query returned %v, expected a throttled error", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("GraphQL.Query made %d calls, expected 1", calls)
+	}
+}
@@ -77,7 +77,7 @@ func TestImageList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/1/images.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/1/images.json",
 		httpmock.NewBytesResponder(200, loadFixture("images.json")))
 
 	images, err := client.Image.List(context.Background(), 1, nil)
@@ -97,10 +97,10 @@ func TestImageCount(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/1/images/count.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/1/images/count.json",
 		httpmock.NewStringResponder(200, `{"count": 2}`))
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/1/images/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/1/images/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
 		httpmock.NewStringResponder(200, `{"count": 1}`))
 
 	cnt, err := client.Image.Count(context.Background(), 1, nil)
@@ -129,7 +129,7 @@ func TestImageGet(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/1/images/1.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/1/images/1.json",
 		httpmock.NewBytesResponder(200, loadFixture("image.json")))
 
 	image, err := client.Image.Get(context.Background(), 1, 1, nil)
@@ -144,7 +144,7 @@ func TestImageCreate(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/products/1/images.json",
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/products/1/images.json",
 		httpmock.NewBytesResponder(200, loadFixture("image.json")))
 
 	variantIds := make([]int, 2)
@@ -167,7 +167,7 @@ func TestImageUpdate(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/products/1/images/1.json",
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/api/2024-10/products/1/images/1.json",
 		httpmock.NewBytesResponder(200, loadFixture("image.json")))
 
 	// Take an existing image
@@ -192,7 +192,7 @@ func TestImageDelete(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/products/1/images/1.json",
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/api/2024-10/products/1/images/1.json",
 		httpmock.NewStringResponder(200, "{}"))
 
 	err := client.Image.Delete(context.Background(), 1, 1)
@@ -0,0 +1,103 @@
+package goshopify
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader is the response header in which Shopify returns the ID of
+// the request, which Shopify support asks for when investigating issues.
+const RequestIDHeader = "X-Request-Id"
+
+// Headers that carry credentials and are never logged.
+var redactedHeaders = []string{
+	"X-Shopify-Access-Token",
+	"Authorization",
+	"Cookie",
+}
+
+// Returns a copy of the headers with the values of credential headers
+// replaced.
+func redactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for _, name := range redactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "REDACTED")
+		}
+	}
+	return redacted
+}
+
+// Middleware that logs a record for every request. It runs outside of the
+// retries, so the duration includes the retries and rate limit waits.
+func (c *Client) logRequests(next DoFunc) DoFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if c.logger == nil {
+			return next(req)
+		}
+
+		ctx := req.Context()
+		start := time.Now()
+		resp, err := next(req)
+		duration := time.Since(start)
+
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("host", req.URL.Host),
+			slog.String("path", req.URL.Path),
+			slog.Duration("duration", duration),
+		}
+
+		if c.logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs,
+				slog.String("url", req.URL.Redacted()),
+				slog.Any("request_headers", redactHeaders(req.Header)))
+		}
+
+		level := slog.LevelInfo
+		if err != nil {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		if resp != nil {
+			attrs = append(attrs,
+				slog.Int("status", resp.StatusCode),
+				slog.String("call_limit", resp.Header.Get(CallLimitHeader)),
+				slog.String("request_id", resp.Header.Get(RequestIDHeader)))
+		}
+
+		c.logger.LogAttrs(ctx, level, "Shopify API call", attrs...)
+
+		if resp != nil && err != nil {
+			c.logErrorBody(ctx, req, resp)
+		}
+
+		return resp, err
+	}
+}
+
+// Logs the body of an unsuccessful response, leaving the body readable.
+func (c *Client) logErrorBody(ctx context.Context, req *http.Request, resp *http.Response) {
+	if !c.logger.Enabled(ctx, c.errorBodyLogLevel) {
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	c.logger.LogAttrs(ctx, c.errorBodyLogLevel, "Shopify API error response",
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("status", resp.StatusCode),
+		slog.String("request_id", resp.Header.Get(RequestIDHeader)),
+		slog.String("body", string(body)))
+}
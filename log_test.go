@@ -0,0 +1,182 @@
+package goshopify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+// Returns a client that logs JSON records into the returned buffer.
+func loggingClient(opts ...Option) (*Client, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	opts = append([]Option{WithLogger(logger), WithRetry(RetryPolicy{})}, opts...)
+	return NewClient(app, "fooshop", "abcd", opts...), buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := make(map[string]interface{})
+		err := json.Unmarshal([]byte(line), &record)
+		if err != nil {
+			t.Fatalf("Invalid log record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogRequests(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/shop.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"shop": {"id": 1}}`)
+			resp.Header.Set(CallLimitHeader, "1/40")
+			resp.Header.Set(RequestIDHeader, "abc-123")
+			return resp, nil
+		})
+
+	testClient, buf := loggingClient()
+	_, err := testClient.Shop.Get(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Shop.Get returned error: %v", err)
+	}
+
+	records := logRecords(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("Logged %d records, expected 1: %s", len(records), buf)
+	}
+
+	record := records[0]
+	expected := map[string]interface{}{
+		"level":      "INFO",
+		"msg":        "Shopify API call",
+		"method":     "GET",
+		"host":       "fooshop.myshopify.com",
+		"path":       "/admin/api/2024-10/shop.json",
+		"status":     float64(200),
+		"call_limit": "1/40",
+		"request_id": "abc-123",
+	}
+	for k, v := range expected {
+		if record[k] != v {
+			t.Errorf("Log record %s = %v, expected %v", k, record[k], v)
+		}
+	}
+
+	if _, ok := record["duration"]; !ok {
+		t.Errorf("Log record has no duration: %v", record)
+	}
+
+	if strings.Contains(buf.String(), "abcd") {
+		t.Errorf("Log record contains the access token: %s", buf)
+	}
+}
+
+func TestLogRequestsRedactsBasicAuth(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		httpmock.NewStringResponder(200, `{}`))
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	testClient := NewClient(app, "fooshop", "", WithLogger(logger))
+
+	err := testClient.Get(context.Background(), "foo", nil, nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+
+	if strings.Contains(buf.String(), app.Password) || !strings.Contains(buf.String(), "REDACTED") {
+		t.Errorf("Log record does not redact the basic auth credentials: %s", buf)
+	}
+}
+
+func TestLogRequestsErrorBody(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		httpmock.NewStringResponder(422, `{"errors": {"title": ["can't be blank"]}}`))
+
+	testClient, buf := loggingClient(WithErrorBodyLogLevel(slog.LevelError))
+	err := testClient.Get(context.Background(), "foo", nil, nil)
+	if err == nil {
+		t.Fatal("Get expected error")
+	}
+
+	records := logRecords(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("Logged %d records, expected 2: %s", len(records), buf)
+	}
+
+	if records[0]["level"] != "WARN" || records[0]["status"] != float64(422) {
+		t.Errorf("Log record %v, expected a warning with status 422", records[0])
+	}
+
+	if records[1]["level"] != "ERROR" || records[1]["body"] != `{"errors": {"title": ["can't be blank"]}}` {
+		t.Errorf("Log record %v, expected the error body at level ERROR", records[1])
+	}
+
+	// The error is still decoded from the body
+	if e, ok := err.(ValidationError); !ok || e.Message != "can't be blank" {
+		t.Errorf("Get returned %#v, expected the decoded ValidationError", err)
+	}
+}
+
+func TestLogRequestsErrorBodyLevelDisabled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		httpmock.NewStringResponder(404, `{"errors": "Not Found"}`))
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	testClient := NewClient(app, "fooshop", "abcd", WithLogger(logger))
+
+	testClient.Get(context.Background(), "foo", nil, nil)
+
+	if records := logRecords(t, buf); len(records) != 1 {
+		t.Errorf("Logged %d records, expected only the API call: %s", len(records), buf)
+	}
+}
+
+func TestLogRequestsRetried(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls,
+			httpmock.NewStringResponder(503, `{"errors": "unavailable"}`),
+			httpmock.NewStringResponder(200, `{}`)))
+
+	testClient, buf := loggingClient(WithRetry(RetryPolicy{MaxAttempts: 2}))
+	err := testClient.Get(context.Background(), "foo", nil, nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+
+	var records []map[string]interface{}
+	for _, record := range logRecords(t, buf) {
+		if record["msg"] == "Shopify API call" {
+			records = append(records, record)
+		}
+	}
+
+	if len(records) != 1 || records[0]["status"] != float64(200) {
+		t.Errorf("Logged API calls %v, expected one with status 200", records)
+	}
+}
@@ -11,6 +11,7 @@ const metafieldsBasePath = "admin/metafields"
 
 type MetafieldService interface {
 	List(context.Context, interface{}) ([]*Metafield, error)
+	ListWithPagination(context.Context, interface{}) ([]*Metafield, *Pagination, error)
 	ListForObject(context.Context, string, interface{}) ([]*Metafield, error)
 	Get(context.Context, int, interface{}) (*Metafield, error)
 	Create(context.Context, *Metafield) (*Metafield, error)
@@ -64,6 +65,13 @@ func (s *MetafieldServiceOp) List(ctx context.Context, options interface{}) ([]*
 	return resource.Metafields, err
 }
 
+func (s *MetafieldServiceOp) ListWithPagination(ctx context.Context, options interface{}) ([]*Metafield, *Pagination, error) {
+	path := fmt.Sprintf("%s.json", metafieldsBasePath)
+	resource := new(MetafieldsResource)
+	pagination, err := s.client.ListWithPagination(ctx, path, resource, options)
+	return resource.Metafields, pagination, err
+}
+
 func (s *MetafieldServiceOp) ListForObject(ctx context.Context, resourcePath string, options interface{}) ([]*Metafield, error) {
 	resourcePath = strings.TrimSuffix(resourcePath, "/")
 	resourcePath = strings.TrimPrefix(resourcePath, "/")
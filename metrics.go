@@ -0,0 +1,220 @@
+package goshopify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives an observation for every API call made by a client. It is
+// kept small so it can be adapted to a metrics library, e.g. by recording the
+// observations in Prometheus histograms and counters labeled by shop, resource
+// and status. ObserveRequest is called concurrently when the client is used
+// concurrently.
+type Metrics interface {
+	ObserveRequest(RequestMetrics)
+}
+
+// RequestMetrics describes a single API call, including all of its attempts.
+type RequestMetrics struct {
+	// The host of the shop, e.g. fooshop.myshopify.com.
+	Shop string
+
+	// The resource and action derived from the request, e.g. orders.list,
+	// orders.get, orders.count, products.images.create or graphql.query.
+	Resource string
+
+	// The HTTP method of the request.
+	Method string
+
+	// The status code of the final response, or zero if no response was
+	// received.
+	StatusCode int
+
+	// The time from sending the first attempt until the final response,
+	// including retries and rate limit waits.
+	Latency time.Duration
+
+	// The number of times the request was retried.
+	Retries int
+
+	// The number of times and the total time the request waited for the rate
+	// limiter.
+	RateLimitWaits    int
+	RateLimitWaitTime time.Duration
+
+	// The error of the call, if any.
+	Err error
+}
+
+// WithMetrics sets the Metrics that receive an observation for every API call
+// of the client.
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.metrics = metrics
+	}
+}
+
+// InMemoryMetrics is a Metrics implementation that keeps all observations in
+// memory, e.g. for tests.
+type InMemoryMetrics struct {
+	mu       sync.Mutex
+	requests []RequestMetrics
+}
+
+// ObserveRequest records the observation.
+func (m *InMemoryMetrics) ObserveRequest(r RequestMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, r)
+}
+
+// Requests returns the recorded observations, in order.
+func (m *InMemoryMetrics) Requests() []RequestMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RequestMetrics(nil), m.requests...)
+}
+
+// Count returns the number of recorded observations for the given resource,
+// e.g. orders.list.
+func (m *InMemoryMetrics) Count(resource string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, r := range m.requests {
+		if r.Resource == resource {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset discards the recorded observations.
+func (m *InMemoryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = nil
+}
+
+// Counters of a single API call, which the built-in middleware update through
+// the request context.
+type callStats struct {
+	retries           int
+	rateLimitWaits    int
+	rateLimitWaitTime time.Duration
+}
+
+type callStatsKey struct{}
+
+// Returns the counters of the API call the context belongs to, if metrics are
+// collected for it.
+func callStatsFrom(ctx context.Context) *callStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*callStats)
+	return stats
+}
+
+// Middleware that reports every API call to the client's Metrics.
+func (c *Client) observe(next DoFunc) DoFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if c.metrics == nil {
+			return next(req)
+		}
+
+		stats := new(callStats)
+		req = req.WithContext(context.WithValue(req.Context(), callStatsKey{}, stats))
+
+		start := time.Now()
+		resp, err := next(req)
+
+		m := RequestMetrics{
+			Shop:              req.URL.Host,
+			Resource:          resourceName(req.Method, req.URL.Path),
+			Method:            req.Method,
+			Latency:           time.Since(start),
+			Retries:           stats.retries,
+			RateLimitWaits:    stats.rateLimitWaits,
+			RateLimitWaitTime: stats.rateLimitWaitTime,
+			Err:               err,
+		}
+		if resp != nil {
+			m.StatusCode = resp.StatusCode
+		}
+		c.metrics.ObserveRequest(m)
+
+		return resp, err
+	}
+}
+
+// Resources that exist once per shop, so a GET without an ID is not a list.
+var singletonResources = map[string]bool{
+	"shop": true,
+}
+
+// Derives a resource name such as orders.list or products.images.count from
+// the method and path of a request. IDs and the API version are left out.
+func resourceName(method, path string) string {
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".json")
+	path = strings.TrimPrefix(path, "admin/")
+	if strings.HasPrefix(path, "api/") {
+		// Drop the version, e.g. api/2024-10/orders
+		parts := strings.SplitN(path, "/", 3)
+		if len(parts) < 3 {
+			return strings.ToLower(method)
+		}
+		path = parts[2]
+	}
+
+	if path == "graphql" {
+		return "graphql.query"
+	}
+
+	var names []string
+	endsWithID := false
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		endsWithID = isID(segment)
+		if !endsWithID {
+			names = append(names, segment)
+		}
+	}
+
+	if len(names) > 0 && names[len(names)-1] == "count" {
+		return strings.Join(names, ".")
+	}
+
+	var action string
+	switch method {
+	case http.MethodGet:
+		action = "list"
+		if endsWithID || (len(names) > 0 && singletonResources[names[len(names)-1]]) {
+			action = "get"
+		}
+	case http.MethodPost:
+		action = "create"
+	case http.MethodPut:
+		action = "update"
+	case http.MethodDelete:
+		action = "delete"
+	default:
+		action = strings.ToLower(method)
+	}
+
+	return strings.Join(append(names, action), ".")
+}
+
+// Reports whether a path segment is a numeric ID.
+func isID(segment string) bool {
+	for _, r := range segment {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return segment != ""
+}
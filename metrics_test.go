@@ -0,0 +1,125 @@
+package goshopify
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestResourceName(t *testing.T) {
+	cases := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{"GET", "/admin/api/2024-10/orders.json", "orders.list"},
+		{"GET", "/admin/api/2024-10/orders/1.json", "orders.get"},
+		{"GET", "/admin/api/2024-10/orders/count.json", "orders.count"},
+		{"POST", "/admin/api/2024-10/orders.json", "orders.create"},
+		{"PUT", "/admin/api/2024-10/orders/1.json", "orders.update"},
+		{"DELETE", "/admin/api/2024-10/orders/1.json", "orders.delete"},
+		{"GET", "/admin/api/2024-10/products/1/images.json", "products.images.list"},
+		{"GET", "/admin/api/2024-10/products/1/images/2.json", "products.images.get"},
+		{"GET", "/admin/api/2024-10/products/1/variants/count.json", "products.variants.count"},
+		{"GET", "/admin/api/2024-10/shop.json", "shop.get"},
+		{"POST", "/admin/api/2024-10/graphql.json", "graphql.query"},
+		{"GET", "/admin/products.json", "products.list"},
+		{"POST", "/admin/oauth/access_token", "oauth.access_token.create"},
+		{"GET", "/foo", "foo.list"},
+	}
+
+	for _, c := range cases {
+		actual := resourceName(c.method, c.path)
+		if actual != c.expected {
+			t.Errorf("resourceName(%q, %q) = %q, expected %q", c.method, c.path, actual, c.expected)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	retrySetup()
+	defer teardown()
+
+	metrics := new(InMemoryMetrics)
+	client.metrics = metrics
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/1.json",
+		sequenceResponder(&calls,
+			httpmock.NewStringResponder(503, `{"errors": "unavailable"}`),
+			httpmock.NewStringResponder(200, `{"order": {"id": 1}}`)))
+
+	_, err := client.Order.Get(context.Background(), 1, nil)
+	if err != nil {
+		t.Fatalf("Order.Get returned error: %v", err)
+	}
+
+	requests := metrics.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Metrics recorded %d requests, expected 1", len(requests))
+	}
+
+	r := requests[0]
+	if r.Shop != "fooshop.myshopify.com" || r.Resource != "orders.get" || r.Method != "GET" ||
+		r.StatusCode != 200 || r.Retries != 1 || r.Err != nil || r.Latency <= 0 {
+		t.Errorf("Metrics recorded %+v", r)
+	}
+
+	if metrics.Count("orders.get") != 1 || metrics.Count("orders.list") != 0 {
+		t.Errorf("Metrics.Count returned unexpected counts for %+v", requests)
+	}
+
+	metrics.Reset()
+	if len(metrics.Requests()) != 0 {
+		t.Error("Metrics.Reset did not discard the requests")
+	}
+}
+
+func TestMetricsError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	metrics := new(InMemoryMetrics)
+	testClient := NewClient(app, "fooshop", "abcd", WithRetry(RetryPolicy{}), WithMetrics(metrics))
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/count.json",
+		httpmock.NewStringResponder(401, `{"errors": "Unauthorized"}`))
+
+	_, err := testClient.Product.Count(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Product.Count expected error")
+	}
+
+	r := metrics.Requests()[0]
+	if r.Resource != "products.count" || r.StatusCode != 401 || r.Err == nil || r.Retries != 0 {
+		t.Errorf("Metrics recorded %+v", r)
+	}
+}
+
+func TestMetricsRateLimitWaits(t *testing.T) {
+	setup()
+	defer teardown()
+
+	metrics := new(InMemoryMetrics)
+	limiter := &RateLimiter{Headroom: 1, LeakRate: 1000}
+	testClient := NewClient(app, "fooshop", "abcd", WithRateLimiter(limiter), WithMetrics(metrics))
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		callLimitResponder("40/40"))
+
+	for i := 0; i < 2; i++ {
+		err := testClient.Get(context.Background(), "foo", nil, nil)
+		if err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+	}
+
+	requests := metrics.Requests()
+	if requests[0].RateLimitWaits != 0 {
+		t.Errorf("First request waited for the rate limiter: %+v", requests[0])
+	}
+	if requests[1].RateLimitWaits != 1 || requests[1].RateLimitWaitTime <= 0 {
+		t.Errorf("Second request did not wait for the rate limiter: %+v", requests[1])
+	}
+}
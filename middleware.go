@@ -0,0 +1,111 @@
+package goshopify
+
+import (
+	"net/http"
+)
+
+// DoFunc sends an API request and returns the response. For unsuccessful
+// responses, the error decoded from the response (e.g. a ResponseError) is
+// returned alongside the response. The body of a successful response is left
+// unread so it can be decoded by the caller, who closes it.
+type DoFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the DoFunc that sends requests, e.g. to inspect or modify
+// requests and responses, or to short-circuit requests altogether. A
+// middleware that discards a response returned by next must close its body.
+type Middleware func(next DoFunc) DoFunc
+
+// WithMiddleware adds middleware to the client, see Client.Use.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.Use(middlewares...)
+	}
+}
+
+// Use appends middleware to the chain that every request of the client passes
+// through. The first middleware is the outermost one. Middleware runs around
+// the built-in retries and rate limiting, so it sees every request once and
+// only the final response. Use must not be called concurrently with requests.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// Returns the DoFunc that sends requests through the middleware chain.
+func (c *Client) handler() DoFunc {
+	h := c.send
+	h = c.rateLimit(h)
+	h = c.retry(h)
+	h = c.logRequests(h)
+	h = c.observe(h)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
+
+// Middleware that waits for the rate limiter before every attempt.
+func (c *Client) rateLimit(next DoFunc) DoFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if c.RateLimiter != nil {
+			waited, err := c.RateLimiter.wait(req.Context(), req.URL.Host)
+			if stats := callStatsFrom(req.Context()); stats != nil && waited > 0 {
+				stats.rateLimitWaits++
+				stats.rateLimitWaitTime += waited
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return next(req)
+	}
+}
+
+// Middleware that retries failed requests according to the RetryPolicy.
+func (c *Client) retry(next DoFunc) DoFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if requestOptionsFrom(req.Context()).DisableRetries {
+			return next(req)
+		}
+
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+			if err == nil {
+				return resp, nil
+			}
+
+			wait, ok := c.Retry.delay(req, attempt, err)
+			if !ok {
+				return resp, err
+			}
+
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			if c.logger != nil {
+				c.logger.DebugContext(req.Context(), "retrying Shopify request",
+					"method", req.Method,
+					"path", req.URL.Path,
+					"attempt", attempt,
+					"wait", wait,
+					"error", err)
+			}
+
+			err = sleepContext(req.Context(), wait)
+			if err != nil {
+				return nil, err
+			}
+
+			if stats := callStatsFrom(req.Context()); stats != nil {
+				stats.retries++
+			}
+
+			if req.GetBody != nil {
+				req.Body, err = req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package goshopify
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next DoFunc) DoFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				resp, err := next(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	client.Use(trace("first"), trace("second"))
+	client.Use(trace("third"))
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		httpmock.NewStringResponder(200, `{}`))
+
+	err := client.Get(context.Background(), "foo", nil, nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+
+	expected := []string{
+		"first before", "second before", "third before",
+		"third after", "second after", "first after",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Middleware calls = %v, expected %v", calls, expected)
+	}
+}
+
+func TestMiddlewareModifiesRequestAndResponse(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var status int
+	var responseErr error
+	testClient := NewClient(app, "fooshop", "abcd",
+		WithRetry(RetryPolicy{}),
+		WithMiddleware(func(next DoFunc) DoFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				req.Header.Set("X-Signature", "signed")
+				resp, err := next(req)
+				if resp != nil {
+					status = resp.StatusCode
+				}
+				responseErr = err
+				return resp, err
+			}
+		}))
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Signature") != "signed" {
+				return httpmock.NewStringResponse(401, `{"errors": "not signed"}`), nil
+			}
+			return httpmock.NewStringResponse(404, `{"errors": "Not Found"}`), nil
+		})
+
+	err := testClient.Get(context.Background(), "foo", nil, nil)
+
+	expected := NotFoundError{ResponseError{Status: 404, Message: "Not Found", Body: []byte(`{"errors": "Not Found"}`)}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Get returned %#v, expected %#v", err, expected)
+	}
+
+	if status != 404 {
+		t.Errorf("Middleware saw status %d, expected 404", status)
+	}
+
+	if !reflect.DeepEqual(responseErr, expected) {
+		t.Errorf("Middleware saw error %#v, expected %#v", responseErr, expected)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// A middleware can answer requests itself, e.g. from a cache.
+	client.Use(func(next DoFunc) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"shop": {"id": 42}}`)),
+			}, nil
+		}
+	})
+
+	shop, err := client.Shop.Get(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Shop.Get returned error: %v", err)
+	}
+
+	if shop.ID != 42 {
+		t.Errorf("Shop.ID returned %d, expected 42", shop.ID)
+	}
+}
+
+func TestMiddlewareSeesFinalAttempt(t *testing.T) {
+	retrySetup()
+	defer teardown()
+
+	seen := 0
+	client.Use(func(next DoFunc) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			seen++
+			return next(req)
+		}
+	})
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls,
+			httpmock.NewStringResponder(500, `{"errors": "oops"}`),
+			httpmock.NewStringResponder(200, `{}`)))
+
+	err := client.Get(context.Background(), "foo", nil, nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+
+	if calls != 2 || seen != 1 {
+		t.Errorf("Get made %d calls and middleware saw %d requests, expected 2 and 1", calls, seen)
+	}
+}
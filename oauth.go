@@ -1,12 +1,19 @@
 package goshopify
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 )
 
+// WebhookHmacHeader is the header that carries the signature of a webhook.
+const WebhookHmacHeader = "X-Shopify-Hmac-Sha256"
+
 // Returns a Shopify oauth authorization url for the given shopname and state.
 //
 // State is a unique value that can be used to check the authenticity during a
@@ -71,3 +78,49 @@ func (app App) VerifyAuthorizationURL(u *url.URL) bool {
 
 	return app.VerifyMessage(message, messageMAC)
 }
+
+// Verify a webhook request against the HMAC in its X-Shopify-Hmac-Sha256
+// header, using the ApiSecret and the PreviousApiSecrets of the app.
+//
+// The body is read and then replaced, so it can still be read by the
+// handler.
+func (app App) VerifyWebhookRequest(httpRequest *http.Request) bool {
+	messageMAC := httpRequest.Header.Get(WebhookHmacHeader)
+	if messageMAC == "" || httpRequest.Body == nil {
+		return false
+	}
+
+	// webhook HMAC is in base64 so it needs to be decoded
+	actualMAC, err := base64.StdEncoding.DecodeString(messageMAC)
+	if err != nil {
+		return false
+	}
+
+	message, err := ioutil.ReadAll(httpRequest.Body)
+	httpRequest.Body.Close()
+	httpRequest.Body = ioutil.NopCloser(bytes.NewReader(message))
+	if err != nil {
+		return false
+	}
+
+	return app.verifyWebhookMessage(message, actualMAC)
+}
+
+// Verify a webhook body against each secret of the app. All secrets are
+// checked, so the time taken doesn't depend on which one matches.
+func (app App) verifyWebhookMessage(message, messageMAC []byte) bool {
+	secrets := append([]string{app.ApiSecret}, app.PreviousApiSecrets...)
+
+	valid := false
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(message)
+		if hmac.Equal(messageMAC, mac.Sum(nil)) {
+			valid = true
+		}
+	}
+	return valid
+}
@@ -1,7 +1,13 @@
 package goshopify
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"gopkg.in/jarcoal/httpmock.v1"
@@ -69,3 +75,53 @@ func TestAppVerifyAuthorizationURL(t *testing.T) {
 		}
 	}
 }
+
+func webhookHmac(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAppVerifyWebhookRequest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	body := `{"id":1,"email":"john@example.com"}`
+
+	cases := []struct {
+		description string
+		app         App
+		hmac        string
+		expected    bool
+	}{
+		{"valid", app, webhookHmac("hush", body), true},
+		{"other secret", app, webhookHmac("other", body), false},
+		{"other body", app, webhookHmac("hush", body+" "), false},
+		{"hex hmac", app, "4712bf92ffc2917d15a2f5a273e39f0116667419aa4b6ac0b3baaf26fa3c4d20", false},
+		{"missing hmac", app, "", false},
+		{"previous secret", App{ApiSecret: "new", PreviousApiSecrets: []string{"hush"}}, webhookHmac("hush", body), true},
+		{"current secret while rotating", App{ApiSecret: "new", PreviousApiSecrets: []string{"hush"}}, webhookHmac("new", body), true},
+		{"no secret", App{}, webhookHmac("", body), false},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest("POST", "https://example.com/webhooks", strings.NewReader(body))
+		if c.hmac != "" {
+			req.Header.Set(WebhookHmacHeader, c.hmac)
+		}
+
+		actual := c.app.VerifyWebhookRequest(req)
+		if actual != c.expected {
+			t.Errorf("App.VerifyWebhookRequest() %s: expected %v, actual %v", c.description, c.expected, actual)
+		}
+
+		// The body can still be read by the handler.
+		read, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("ioutil.ReadAll(): %v", err)
+		}
+		if string(read) != body {
+			t.Errorf("App.VerifyWebhookRequest() %s: body = %q, expected %q", c.description, read, body)
+		}
+	}
+}
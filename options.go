@@ -0,0 +1,84 @@
+package goshopify
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// Option configures a Client created with NewClient.
+type Option func(c *Client)
+
+// WithAPIVersion sets the Admin API version that is injected into the paths of
+// all requests, e.g. admin/orders.json becomes
+// admin/api/2024-10/orders.json. An empty version results in unversioned
+// requests.
+func WithAPIVersion(version string) Option {
+	return func(c *Client) {
+		c.apiVersion = version
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests instead of
+// http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.Client = httpClient
+	}
+}
+
+// WithBaseURL sets the URL that request paths are resolved against instead of
+// the shop's myshopify.com URL, e.g. to point the client at a local stand-in
+// for Shopify. The URL should end with a slash if it has a path. An invalid
+// URL makes every request of the client fail.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			c.err = fmt.Errorf("invalid base URL: %v", err)
+			return
+		}
+		c.baseURL = u
+	}
+}
+
+// WithRetry sets the policy used to retry failed requests instead of
+// DefaultRetryPolicy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = policy
+	}
+}
+
+// WithRateLimiter sets the rate limiter of the client, e.g. to share it with
+// other clients for the same shop.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.RateLimiter = limiter
+	}
+}
+
+// WithLogger sets the logger the client reports to. A record with the method,
+// path, status, duration, call limit and request ID is logged for every API
+// call, as well as retries. Nothing is logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithErrorBodyLogLevel sets the level at which the bodies of unsuccessful
+// responses are logged. The default is slog.LevelDebug.
+func WithErrorBodyLogLevel(level slog.Level) Option {
+	return func(c *Client) {
+		c.errorBodyLogLevel = level
+	}
+}
+
+// WithUserAgent sets the User-Agent header of requests instead of UserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
@@ -0,0 +1,107 @@
+package goshopify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithBaseURLAndHTTPClient(t *testing.T) {
+	var userAgent, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		path = r.URL.Path
+		fmt.Fprint(w, `{"shop": {"id": 1}}`)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Timeout: time.Second}
+	testClient := NewClient(app, "fooshop", "abcd",
+		WithBaseURL(server.URL),
+		WithHTTPClient(httpClient),
+		WithUserAgent("myapp/1.0"),
+		WithAPIVersion("2023-10"))
+
+	if testClient.Client != httpClient {
+		t.Errorf("NewClient Client = %v, expected %v", testClient.Client, httpClient)
+	}
+
+	shop, err := testClient.Shop.Get(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Shop.Get returned error: %v", err)
+	}
+
+	if shop.ID != 1 {
+		t.Errorf("Shop.ID returned %v, expected 1", shop.ID)
+	}
+
+	expected := "/admin/api/2023-10/shop.json"
+	if path != expected {
+		t.Errorf("Request path = %v, expected %v", path, expected)
+	}
+
+	if userAgent != "myapp/1.0" {
+		t.Errorf("Request User-Agent = %v, expected %v", userAgent, "myapp/1.0")
+	}
+}
+
+func TestWithBaseURLInvalid(t *testing.T) {
+	testClient := NewClient(app, "fooshop", "abcd", WithBaseURL("http://foo\x7f.com"))
+
+	_, err := testClient.NewRequest("GET", "admin/shop.json", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid base URL") {
+		t.Errorf("NewRequest returned error %v, expected invalid base URL error", err)
+	}
+}
+
+func TestWithRetryAndRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter()
+	policy := RetryPolicy{MaxAttempts: 7}
+	testClient := NewClient(app, "fooshop", "abcd", WithRetry(policy), WithRateLimiter(limiter))
+
+	if testClient.Retry != policy {
+		t.Errorf("NewClient Retry = %+v, expected %+v", testClient.Retry, policy)
+	}
+
+	if testClient.RateLimiter != limiter {
+		t.Errorf("NewClient RateLimiter = %v, expected %v", testClient.RateLimiter, limiter)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"errors": "unavailable"}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	testClient := NewClient(app, "fooshop", "abcd",
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithLogger(logger),
+		WithRetry(RetryPolicy{MaxAttempts: 2}))
+
+	err := testClient.Get(context.Background(), "admin/shop.json", nil, nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "retrying Shopify request") {
+		t.Errorf("Logger output %q, expected a retry to be logged", buf.String())
+	}
+}
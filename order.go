@@ -15,6 +15,10 @@ const ordersBasePath = "admin/orders"
 // See: https://help.shopify.com/api/reference/order
 type OrderService interface {
 	List(context.Context, interface{}) ([]*Order, error)
+	ListWithPagination(context.Context, interface{}) ([]*Order, *Pagination, error)
+	ListAll(context.Context, interface{}) ([]*Order, error)
+	Iterate(context.Context, interface{}, func(*Order) error) error
+	Stream(context.Context, interface{}, func(*Order) error) error
 	Count(context.Context, interface{}) (int, error)
 	Get(context.Context, int, interface{}) (*Order, error)
 }
@@ -28,6 +32,7 @@ type OrderServiceOp struct {
 // A struct for all available order list options.
 // See: https://help.shopify.com/api/reference/order#index
 type OrderListOptions struct {
+	PageInfo          string    `url:"page_info,omitempty"`
 	Page              int       `url:"page,omitempty"`
 	Limit             int       `url:"limit,omitempty"`
 	SinceID           int       `url:"since_id,omitempty"`
@@ -204,6 +209,50 @@ func (s *OrderServiceOp) List(ctx context.Context, options interface{}) ([]*Orde
 	return resource.Orders, err
 }
 
+// ListWithPagination lists orders and returns the pagination to retrieve
+// the next and previous pages.
+func (s *OrderServiceOp) ListWithPagination(ctx context.Context, options interface{}) ([]*Order, *Pagination, error) {
+	path := fmt.Sprintf("%s.json", ordersBasePath)
+	resource := new(OrdersResource)
+	pagination, err := s.client.ListWithPagination(ctx, path, resource, options)
+	return resource.Orders, pagination, err
+}
+
+// ListAll lists all orders, fetching page after page until there are no more
+// orders.
+func (s *OrderServiceOp) ListAll(ctx context.Context, options interface{}) ([]*Order, error) {
+	var collected []*Order
+	err := s.Iterate(ctx, options, func(o *Order) error {
+		collected = append(collected, o)
+		return nil
+	})
+	return collected, err
+}
+
+// Iterate calls fn for every order, lazily fetching page after page until
+// there are no more orders, fn returns an error or the context is done.
+// Return ErrStopIteration from fn to stop early without an error.
+func (s *OrderServiceOp) Iterate(ctx context.Context, options interface{}, fn func(*Order) error) error {
+	return s.iterate(ctx, options, fn, false)
+}
+
+// Stream is like Iterate, but decodes the orders one by one while the pages
+// are read, so only a single order is kept in memory at a time. fn is called
+// while the response is still open, so the time it takes counts against the
+// timeout of the HTTP client and of the RequestOptions, and keeps the
+// connection busy.
+func (s *OrderServiceOp) Stream(ctx context.Context, options interface{}, fn func(*Order) error) error {
+	return s.iterate(ctx, options, fn, true)
+}
+
+func (s *OrderServiceOp) iterate(ctx context.Context, options interface{}, fn func(*Order) error, stream bool) error {
+	path := fmt.Sprintf("%s.json", ordersBasePath)
+	newElement := func() interface{} { return new(Order) }
+	return s.client.iterate(ctx, path, "orders", options, newElement, func(o interface{}) error {
+		return fn(o.(*Order))
+	}, stream)
+}
+
 // Count orders
 func (s *OrderServiceOp) Count(ctx context.Context, options interface{}) (int, error) {
 	path := fmt.Sprintf("%s/count.json", ordersBasePath)
@@ -2,6 +2,7 @@ package goshopify
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
@@ -66,7 +67,7 @@ func TestOrderList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json",
 		httpmock.NewBytesResponder(200, loadFixture("orders.json")))
 
 	orders, err := client.Order.List(context.Background(), nil)
@@ -87,7 +88,7 @@ func TestOrderListOptions(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json?limit=250&page=10&status=any",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=250&page=10&status=any",
 		httpmock.NewBytesResponder(200, loadFixture("orders.json")))
 
 	options := OrderListOptions{
@@ -113,7 +114,7 @@ func TestOrderGet(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders/123456.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/123456.json",
 		httpmock.NewBytesResponder(200, loadFixture("order.json")))
 
 	order, err := client.Order.Get(context.Background(), 123456, nil)
@@ -136,7 +137,7 @@ func TestOrderGetWithTransactions(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders/123456.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/123456.json",
 		httpmock.NewBytesResponder(200, loadFixture("order_with_transaction.json")))
 
 	options := struct {
@@ -161,14 +162,98 @@ func TestOrderGetWithTransactions(t *testing.T) {
 	transactionTest(t, order.Transactions[0])
 }
 
+func TestOrderListWithPagination(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=1&page_info=abc",
+		linkResponder(200, string(loadFixture("orders.json")),
+			`<https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=1&page_info=def>; rel="next"`))
+
+	orders, pagination, err := client.Order.ListWithPagination(context.Background(), OrderListOptions{PageInfo: "abc", Limit: 1})
+	if err != nil {
+		t.Errorf("Order.ListWithPagination returned error: %v", err)
+	}
+
+	if len(orders) != 1 {
+		t.Errorf("Order.ListWithPagination got %v orders, expected: 1", len(orders))
+	}
+
+	if pagination.NextPageOptions == nil || pagination.NextPageOptions.PageInfo != "def" {
+		t.Errorf("Order.ListWithPagination NextPageOptions = %+v, expected page_info def", pagination.NextPageOptions)
+	}
+
+	if pagination.PreviousPageOptions != nil {
+		t.Errorf("Order.ListWithPagination PreviousPageOptions = %+v, expected nil", pagination.PreviousPageOptions)
+	}
+}
+
+func TestOrderIterate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=2&status=any",
+		linkResponder(200, `{"orders": [{"id":1},{"id":2}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=2&page_info=page2>; rel="next"`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=2&page_info=page2",
+		linkResponder(200, `{"orders": [{"id":3},{"id":4}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=2&page_info=page3>; rel="next"`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=2&page_info=page3",
+		linkResponder(200, `{"orders": [{"id":5}]}`, ""))
+
+	options := OrderListOptions{Limit: 2, Status: "any"}
+
+	var ids []int
+	err := client.Order.Iterate(context.Background(), options, func(order *Order) error {
+		ids = append(ids, order.ID)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Order.Iterate returned error: %v", err)
+	}
+
+	expected := []int{1, 2, 3, 4, 5}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Order.Iterate visited %v, expected %v", ids, expected)
+	}
+
+	// Stop after the third order, the last page should not be fetched.
+	ids = nil
+	err = client.Order.Iterate(context.Background(), options, func(order *Order) error {
+		ids = append(ids, order.ID)
+		if order.ID == 3 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Order.Iterate returned error: %v", err)
+	}
+
+	expected = []int{1, 2, 3}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Order.Iterate visited %v, expected %v", ids, expected)
+	}
+
+	// A cancelled context stops the iteration.
+	ctx, cancel := context.WithCancel(context.Background())
+	err = client.Order.Iterate(ctx, options, func(order *Order) error {
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("Order.Iterate returned %v, expected %v", err, context.Canceled)
+	}
+}
+
 func TestOrderCount(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders/count.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/count.json",
 		httpmock.NewStringResponder(200, `{"count": 7}`))
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
 		httpmock.NewStringResponder(200, `{"count": 2}`))
 
 	cnt, err := client.Order.Count(context.Background(), nil)
@@ -0,0 +1,140 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrStopIteration can be returned from the callback of an Iterate method to
+// stop iterating without Iterate returning an error.
+var ErrStopIteration = errors.New("stop iteration")
+
+// Pagination holds the options to request the next and previous pages of a
+// cursor-paginated collection. Either option is nil if there is no such page.
+// See: https://help.shopify.com/api/guides/paginated-rest-results
+type Pagination struct {
+	NextPageOptions     *ListOptions
+	PreviousPageOptions *ListOptions
+}
+
+// Parses a Link header of the form:
+//
+//	<https://fooshop.myshopify.com/admin/products.json?limit=50&page_info=abc>; rel="next",
+//	<https://fooshop.myshopify.com/admin/products.json?limit=50&page_info=def>; rel="previous"
+//
+// into a Pagination. An empty header results in an empty Pagination.
+func extractPagination(linkHeader string) (*Pagination, error) {
+	pagination := new(Pagination)
+
+	if linkHeader == "" {
+		return pagination, nil
+	}
+
+	// The URLs can contain commas, e.g. in fields=id,title, so the links are
+	// split on the angle brackets around the URLs rather than on commas.
+	rest := linkHeader
+	for strings.TrimSpace(rest) != "" {
+		link := strings.TrimLeft(rest, " ,")
+		end := strings.Index(link, ">")
+		if !strings.HasPrefix(link, "<") || end < 0 {
+			return nil, fmt.Errorf("invalid link in Link header: %q", link)
+		}
+		target := link[1:end]
+
+		params := link[end+1:]
+		rest = ""
+		if next := strings.Index(params, ","); next >= 0 {
+			params, rest = params[:next], params[next+1:]
+		}
+		link = link[:end+1] + params
+
+		parts := strings.Split(params, ";")
+		if len(parts) < 2 || strings.TrimSpace(parts[0]) != "" {
+			return nil, fmt.Errorf("invalid link in Link header: %q", link)
+		}
+
+		rel := ""
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "rel=") {
+				rel = strings.Trim(strings.TrimPrefix(param, "rel="), `"`)
+			}
+		}
+
+		// Only the next and previous links are cursors with a page_info.
+		if rel != "next" && rel != "previous" {
+			continue
+		}
+
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, err
+		}
+
+		q := u.Query()
+		options := &ListOptions{PageInfo: q.Get("page_info"), Fields: q.Get("fields")}
+		if options.PageInfo == "" {
+			return nil, fmt.Errorf("no page_info in Link header: %q", link)
+		}
+
+		if limit := q.Get("limit"); limit != "" {
+			options.Limit, err = strconv.Atoi(limit)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if rel == "next" {
+			pagination.NextPageOptions = options
+		} else {
+			pagination.PreviousPageOptions = options
+		}
+	}
+
+	return pagination, nil
+}
+
+// Fetches page after page of a cursor-paginated collection, starting with the
+// given options, until there is no next page or the context is done, and
+// visits the elements of the array in the given key. If stream is set, the
+// elements are visited while the response is read, see StreamList. Otherwise
+// a page is decoded completely and the response closed before its elements
+// are visited, so slow visits don't hold the connection or count against the
+// timeouts of the request.
+func (c *Client) iterate(ctx context.Context, path, key string, options interface{}, newElement func() interface{}, visit func(interface{}) error, stream bool) error {
+	for {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		var pagination *Pagination
+		if stream {
+			pagination, err = c.StreamList(ctx, path, key, options, newElement, visit)
+		} else {
+			var page []interface{}
+			pagination, err = c.StreamList(ctx, path, key, options, newElement, func(v interface{}) error {
+				page = append(page, v)
+				return nil
+			})
+			for i := 0; err == nil && i < len(page); i++ {
+				err = visit(page[i])
+			}
+		}
+		if err == ErrStopIteration {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if pagination.NextPageOptions == nil {
+			return nil
+		}
+		options = pagination.NextPageOptions
+	}
+}
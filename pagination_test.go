@@ -0,0 +1,156 @@
+package goshopify
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func linkResponder(status int, body, link string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(status, body)
+		if link != "" {
+			resp.Header.Set("Link", link)
+		}
+		return resp, nil
+	}
+}
+
+func TestExtractPagination(t *testing.T) {
+	cases := []struct {
+		header   string
+		expected *Pagination
+		err      bool
+	}{
+		{
+			"",
+			&Pagination{},
+			false,
+		},
+		{
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=1&page_info=next123>; rel="next"`,
+			&Pagination{NextPageOptions: &ListOptions{PageInfo: "next123", Limit: 1}},
+			false,
+		},
+		{
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?page_info=prev123>; rel="previous", <https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=250&page_info=next123>; rel="next"`,
+			&Pagination{
+				NextPageOptions:     &ListOptions{PageInfo: "next123", Limit: 250},
+				PreviousPageOptions: &ListOptions{PageInfo: "prev123"},
+			},
+			false,
+		},
+		{
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=50&fields=id,title&page_info=prev123>; rel="previous", <https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=50&fields=id,title&page_info=next123>; rel="next"`,
+			&Pagination{
+				NextPageOptions:     &ListOptions{PageInfo: "next123", Limit: 50, Fields: "id,title"},
+				PreviousPageOptions: &ListOptions{PageInfo: "prev123", Limit: 50, Fields: "id,title"},
+			},
+			false,
+		},
+		{
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json>; rel="canonical", <https://fooshop.myshopify.com/admin/api/2024-10/products.json?page_info=next123>; rel="next"`,
+			&Pagination{NextPageOptions: &ListOptions{PageInfo: "next123"}},
+			false,
+		},
+		{
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=foo>; rel="canonical"`,
+			&Pagination{},
+			false,
+		},
+		{
+			`https://fooshop.myshopify.com/admin/api/2024-10/products.json?page_info=abc; rel="next"`,
+			nil,
+			true,
+		},
+		{
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=1>; rel="next"`,
+			nil,
+			true,
+		},
+		{
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=foo&page_info=abc>; rel="next"`,
+			nil,
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := extractPagination(c.header)
+		if (err != nil) != c.err {
+			t.Errorf("extractPagination(%q) err = %v, expected error: %v", c.header, err, c.err)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("extractPagination(%q) = %#v, expected %#v", c.header, actual, c.expected)
+		}
+	}
+}
+
+func TestListWithPagination(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo.json?limit=2&page_info=abc",
+		linkResponder(200, `{"foos": [{"id": 3}]}`,
+			`<https://fooshop.myshopify.com/foo.json?limit=2&page_info=prev>; rel="previous"`))
+
+	resource := struct {
+		Foos []struct {
+			ID int `json:"id"`
+		} `json:"foos"`
+	}{}
+
+	pagination, err := client.ListWithPagination(context.Background(), "foo.json", &resource, ListOptions{PageInfo: "abc", Limit: 2})
+	if err != nil {
+		t.Fatalf("Client.ListWithPagination returned error: %v", err)
+	}
+
+	if len(resource.Foos) != 1 || resource.Foos[0].ID != 3 {
+		t.Errorf("Client.ListWithPagination decoded %+v, expected a single foo with ID 3", resource)
+	}
+
+	expected := &Pagination{PreviousPageOptions: &ListOptions{PageInfo: "prev", Limit: 2}}
+	if !reflect.DeepEqual(pagination, expected) {
+		t.Errorf("Client.ListWithPagination returned %#v, expected %#v", pagination, expected)
+	}
+}
+
+func TestListWithPaginationError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo.json",
+		linkResponder(200, `{}`, `<invalid`))
+
+	_, err := client.ListWithPagination(context.Background(), "foo.json", nil, nil)
+	if err == nil {
+		t.Error("Client.ListWithPagination expected error for invalid Link header")
+	}
+}
+
+func TestIterateKeepsFields(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products.json?fields=id%2Ctitle&limit=1",
+		linkResponder(200, `{"products": [{"id": 1, "title": "One"}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=1&fields=id,title&page_info=next>; rel="next"`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products.json?fields=id%2Ctitle&limit=1&page_info=next",
+		httpmock.NewStringResponder(200, `{"products": [{"id": 2, "title": "Two"}]}`))
+
+	var ids []int
+	err := client.Product.Iterate(context.Background(), ListOptions{Limit: 1, Fields: "id,title"}, func(p *Product) error {
+		ids = append(ids, p.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Product.Iterate returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("Product.Iterate visited %v, expected [1 2]", ids)
+	}
+}
@@ -13,6 +13,10 @@ const productsBasePath = "admin/products"
 // See: https://help.shopify.com/api/reference/product
 type ProductService interface {
 	List(context.Context, interface{}) ([]*Product, error)
+	ListWithPagination(context.Context, interface{}) ([]*Product, *Pagination, error)
+	ListAll(context.Context, interface{}) ([]*Product, error)
+	Iterate(context.Context, interface{}, func(*Product) error) error
+	Stream(context.Context, interface{}, func(*Product) error) error
 	Count(context.Context, interface{}) (int, error)
 	Get(context.Context, int, interface{}) (*Product, error)
 	Create(context.Context, *Product) (*Product, error)
@@ -72,6 +76,50 @@ func (s *ProductServiceOp) List(ctx context.Context, options interface{}) ([]*Pr
 	return resource.Products, err
 }
 
+// ListWithPagination lists products and returns the pagination to retrieve
+// the next and previous pages.
+func (s *ProductServiceOp) ListWithPagination(ctx context.Context, options interface{}) ([]*Product, *Pagination, error) {
+	path := fmt.Sprintf("%s.json", productsBasePath)
+	resource := new(ProductsResource)
+	pagination, err := s.client.ListWithPagination(ctx, path, resource, options)
+	return resource.Products, pagination, err
+}
+
+// ListAll lists all products, fetching page after page until there are no more
+// products.
+func (s *ProductServiceOp) ListAll(ctx context.Context, options interface{}) ([]*Product, error) {
+	var collected []*Product
+	err := s.Iterate(ctx, options, func(p *Product) error {
+		collected = append(collected, p)
+		return nil
+	})
+	return collected, err
+}
+
+// Iterate calls fn for every product, lazily fetching page after page until
+// there are no more products, fn returns an error or the context is done.
+// Return ErrStopIteration from fn to stop early without an error.
+func (s *ProductServiceOp) Iterate(ctx context.Context, options interface{}, fn func(*Product) error) error {
+	return s.iterate(ctx, options, fn, false)
+}
+
+// Stream is like Iterate, but decodes the products one by one while the pages
+// are read, so only a single product is kept in memory at a time. fn is called
+// while the response is still open, so the time it takes counts against the
+// timeout of the HTTP client and of the RequestOptions, and keeps the
+// connection busy.
+func (s *ProductServiceOp) Stream(ctx context.Context, options interface{}, fn func(*Product) error) error {
+	return s.iterate(ctx, options, fn, true)
+}
+
+func (s *ProductServiceOp) iterate(ctx context.Context, options interface{}, fn func(*Product) error, stream bool) error {
+	path := fmt.Sprintf("%s.json", productsBasePath)
+	newElement := func() interface{} { return new(Product) }
+	return s.client.iterate(ctx, path, "products", options, newElement, func(p interface{}) error {
+		return fn(p.(*Product))
+	}, stream)
+}
+
 // Count products
 func (s *ProductServiceOp) Count(ctx context.Context, options interface{}) (int, error) {
 	path := fmt.Sprintf("%s/count.json", productsBasePath)
@@ -21,7 +21,7 @@ func TestProductList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products.json",
 		httpmock.NewStringResponder(200, `{"products": [{"id":1},{"id":2}]}`))
 
 	products, err := client.Product.List(context.Background(), nil)
@@ -35,14 +35,60 @@ func TestProductList(t *testing.T) {
 	}
 }
 
+func TestProductListWithPagination(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=2",
+		linkResponder(200, `{"products": [{"id":1},{"id":2}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?limit=2&page_info=def>; rel="next"`))
+
+	products, pagination, err := client.Product.ListWithPagination(context.Background(), ListOptions{Limit: 2})
+	if err != nil {
+		t.Errorf("Product.ListWithPagination returned error: %v", err)
+	}
+
+	expected := []*Product{{ID: 1}, {ID: 2}}
+	if !reflect.DeepEqual(products, expected) {
+		t.Errorf("Product.ListWithPagination returned %+v, expected %+v", products, expected)
+	}
+
+	expectedPagination := &Pagination{NextPageOptions: &ListOptions{PageInfo: "def", Limit: 2}}
+	if !reflect.DeepEqual(pagination, expectedPagination) {
+		t.Errorf("Product.ListWithPagination returned %+v, expected %+v", pagination, expectedPagination)
+	}
+}
+
+func TestProductListAll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products.json",
+		linkResponder(200, `{"products": [{"id":1},{"id":2}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?page_info=page2>; rel="next"`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products.json?page_info=page2",
+		linkResponder(200, `{"products": [{"id":3}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/products.json?page_info=page1>; rel="previous"`))
+
+	products, err := client.Product.ListAll(context.Background(), nil)
+	if err != nil {
+		t.Errorf("Product.ListAll returned error: %v", err)
+	}
+
+	expected := []*Product{{ID: 1}, {ID: 2}, {ID: 3}}
+	if !reflect.DeepEqual(products, expected) {
+		t.Errorf("Product.ListAll returned %+v, expected %+v", products, expected)
+	}
+}
+
 func TestProductCount(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/count.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/count.json",
 		httpmock.NewStringResponder(200, `{"count": 3}`))
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/count.json?created_at_min=2016-01-01T00%3A00%3A00Z",
 		httpmock.NewStringResponder(200, `{"count": 2}`))
 
 	cnt, err := client.Product.Count(context.Background(), nil)
@@ -71,7 +117,7 @@ func TestProductGet(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/1.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/products/1.json",
 		httpmock.NewStringResponder(200, `{"product": {"id":1}}`))
 
 	product, err := client.Product.Get(context.Background(), 1, nil)
@@ -89,7 +135,7 @@ func TestProductCreate(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/products.json",
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/products.json",
 		httpmock.NewBytesResponder(200, loadFixture("product.json")))
 
 	product := &Product{
@@ -111,7 +157,7 @@ func TestProductUpdate(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/products/1.json",
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/api/2024-10/products/1.json",
 		httpmock.NewBytesResponder(200, loadFixture("product.json")))
 
 	product := &Product{
@@ -131,7 +177,7 @@ func TestProductDelete(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/products/1.json",
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/api/2024-10/products/1.json",
 		httpmock.NewStringResponder(200, "{}"))
 
 	err := client.Product.Delete(context.Background(), 1)
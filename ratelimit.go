@@ -0,0 +1,276 @@
+package goshopify
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CallLimitHeader is the response header in which Shopify reports the fill
+// level of a shop's API call bucket, e.g. "32/40".
+const CallLimitHeader = "X-Shopify-Shop-Api-Call-Limit"
+
+// CallLimit represents the fill level of a shop's API call bucket.
+type CallLimit struct {
+	Used int
+	Max  int
+}
+
+// Parses the value of the X-Shopify-Shop-Api-Call-Limit header.
+func parseCallLimit(s string) (CallLimit, bool) {
+	var l CallLimit
+	_, err := fmt.Sscanf(s, "%d/%d", &l.Used, &l.Max)
+	if err != nil || l.Max <= 0 {
+		return CallLimit{}, false
+	}
+	return l, true
+}
+
+// RateLimiter tracks the leaky buckets Shopify uses to throttle REST calls,
+// based on the X-Shopify-Shop-Api-Call-Limit headers of the responses. A
+// bucket is kept per shop so a single RateLimiter can be shared between
+// multiple clients, both for the same and for different shops.
+//
+// A RateLimiter only tracks the buckets by default. Set Headroom to make
+// clients wait before sending requests to a shop whose bucket is (nearly)
+// full.
+//
+// The GraphQL Admin API is throttled by query cost instead, see
+// GraphQLThrottleStatus. The RateLimiter tracks the available points per shop
+// as well and waits for enough points to be restored before sending a query
+// when ThrottleGraphQL is set.
+type RateLimiter struct {
+	// The number of calls to keep free in the bucket. Requests block until
+	// the bucket has drained far enough. Zero disables blocking.
+	Headroom int
+
+	// The number of calls that drain from the bucket per second. When zero,
+	// the rate is derived from the bucket size, i.e. 2 calls per second for a
+	// bucket of 40 and 20 calls per second for a bucket of 400.
+	LeakRate float64
+
+	// Wait before sending a GraphQL query until the shop has enough points
+	// available for the query's cost, and retry queries that were throttled
+	// anyway. The cost of a query is known once it has been sent before.
+	ThrottleGraphQL bool
+
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	points     map[string]*pointBucket
+	queryCosts map[string]int
+}
+
+// The maximum number of query costs a RateLimiter remembers.
+const maxQueryCosts = 1000
+
+type bucket struct {
+	used    float64
+	max     int
+	updated time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that tracks the buckets of the shops
+// it is used for, without blocking.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{}
+}
+
+// CallLimit returns the estimated current fill level of the bucket for the
+// given shop, taking into account the calls drained since the last response.
+// The second return value is false if no response for the shop has been seen
+// yet.
+func (l *RateLimiter) CallLimit(shopName string) (CallLimit, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[ShopFullName(shopName)]
+	if !ok {
+		return CallLimit{}, false
+	}
+
+	used := int(math.Ceil(l.fill(b, time.Now())))
+	return CallLimit{Used: used, Max: b.max}, true
+}
+
+// Wait blocks until the bucket for the given shop has room for another
+// request, or until the context is done. The request is accounted for in the
+// bucket when Wait returns without error.
+func (l *RateLimiter) Wait(ctx context.Context, shopName string) error {
+	_, err := l.wait(ctx, shopName)
+	return err
+}
+
+// Same as Wait, but also returns the time spent sleeping.
+func (l *RateLimiter) wait(ctx context.Context, shopName string) (time.Duration, error) {
+	shopName = ShopFullName(shopName)
+	var waited time.Duration
+
+	for {
+		l.mu.Lock()
+		b, ok := l.buckets[shopName]
+		if !ok {
+			l.mu.Unlock()
+			return waited, nil
+		}
+
+		now := time.Now()
+		used := l.fill(b, now)
+		excess := used + 1 - float64(b.max-l.Headroom)
+		if l.Headroom <= 0 || excess <= 0 {
+			b.used = used + 1
+			b.updated = now
+			l.mu.Unlock()
+			return waited, nil
+		}
+
+		wait := time.Duration(excess / l.leakRate(b) * float64(time.Second))
+		l.mu.Unlock()
+
+		start := time.Now()
+		err := sleepContext(ctx, wait)
+		waited += time.Since(start)
+		if err != nil {
+			return waited, err
+		}
+	}
+}
+
+// Updates the bucket of the given shop from the headers of a response.
+func (l *RateLimiter) update(shopName string, resp *http.Response) {
+	limit, ok := parseCallLimit(resp.Header.Get(CallLimitHeader))
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+
+	used := float64(limit.Used)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		used = float64(limit.Max)
+	}
+
+	l.buckets[ShopFullName(shopName)] = &bucket{
+		used:    used,
+		max:     limit.Max,
+		updated: time.Now(),
+	}
+}
+
+// Returns the estimated fill level of the bucket at the given time.
+func (l *RateLimiter) fill(b *bucket, now time.Time) float64 {
+	drained := now.Sub(b.updated).Seconds() * l.leakRate(b)
+	return math.Max(0, b.used-drained)
+}
+
+func (l *RateLimiter) leakRate(b *bucket) float64 {
+	if l.LeakRate > 0 {
+		return l.LeakRate
+	}
+	return float64(b.max) / 20
+}
+
+type pointBucket struct {
+	status  GraphQLThrottleStatus
+	updated time.Time
+}
+
+// GraphQLStatus returns the estimated current throttle status of the GraphQL
+// API for the given shop, taking into account the points restored since the
+// last response. The second return value is false if no GraphQL response for
+// the shop has been seen yet.
+func (l *RateLimiter) GraphQLStatus(shopName string) (GraphQLThrottleStatus, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.points[ShopFullName(shopName)]
+	if !ok {
+		return GraphQLThrottleStatus{}, false
+	}
+
+	status := b.status
+	status.CurrentlyAvailable = math.Floor(b.available(time.Now()))
+	return status, true
+}
+
+// Blocks until the shop has enough points available for the expected cost of
+// the query, or until the context is done. The cost is reserved when
+// waitGraphQL returns without error.
+func (l *RateLimiter) waitGraphQL(ctx context.Context, shopName, query string) error {
+	if !l.ThrottleGraphQL {
+		return nil
+	}
+
+	shopName = ShopFullName(shopName)
+
+	for {
+		l.mu.Lock()
+		b, ok := l.points[shopName]
+		cost := float64(l.queryCosts[query])
+		if !ok || cost == 0 || b.status.RestoreRate <= 0 {
+			l.mu.Unlock()
+			return nil
+		}
+
+		// A query that costs more than the maximum would never be sent.
+		cost = math.Min(cost, b.status.MaximumAvailable)
+
+		now := time.Now()
+		available := b.available(now)
+		if available >= cost {
+			b.status.CurrentlyAvailable = available - cost
+			b.updated = now
+			l.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((cost - available) / b.status.RestoreRate * float64(time.Second))
+		l.mu.Unlock()
+
+		err := sleepContext(ctx, wait)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Updates the points of the given shop and the cost of the query from the
+// cost extension of a GraphQL response.
+func (l *RateLimiter) updateGraphQL(shopName, query string, cost *GraphQLCost) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.points == nil {
+		l.points = make(map[string]*pointBucket)
+		l.queryCosts = make(map[string]int)
+	}
+
+	l.points[ShopFullName(shopName)] = &pointBucket{
+		status:  cost.ThrottleStatus,
+		updated: time.Now(),
+	}
+
+	// Queries with inlined arguments are all different, so the costs are
+	// capped to keep a shared RateLimiter from growing without bound. An
+	// evicted cost is learned again the next time its query is sent.
+	if _, ok := l.queryCosts[query]; !ok && len(l.queryCosts) >= maxQueryCosts {
+		for q := range l.queryCosts {
+			delete(l.queryCosts, q)
+			break
+		}
+	}
+	l.queryCosts[query] = cost.RequestedQueryCost
+}
+
+// Returns the estimated available points at the given time.
+func (b *pointBucket) available(now time.Time) float64 {
+	restored := now.Sub(b.updated).Seconds() * b.status.RestoreRate
+	return math.Min(b.status.MaximumAvailable, b.status.CurrentlyAvailable+restored)
+}
@@ -0,0 +1,148 @@
+package goshopify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func callLimitResponder(limit string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(200, `{}`)
+		resp.Header.Set(CallLimitHeader, limit)
+		return resp, nil
+	}
+}
+
+func TestParseCallLimit(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected CallLimit
+		ok       bool
+	}{
+		{"32/40", CallLimit{Used: 32, Max: 40}, true},
+		{"1/400", CallLimit{Used: 1, Max: 400}, true},
+		{"", CallLimit{}, false},
+		{"foo", CallLimit{}, false},
+		{"1/0", CallLimit{}, false},
+	}
+
+	for _, c := range cases {
+		actual, ok := parseCallLimit(c.in)
+		if actual != c.expected || ok != c.ok {
+			t.Errorf("parseCallLimit(%q) = %v, %v, expected %v, %v", c.in, actual, ok, c.expected, c.ok)
+		}
+	}
+}
+
+func TestClientCallLimit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	if limit := client.CallLimit(); limit != (CallLimit{}) {
+		t.Errorf("Client.CallLimit() = %v before any request, expected zero value", limit)
+	}
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo", callLimitResponder("39/40"))
+
+	err := client.Get(context.Background(), "foo", nil, nil)
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+
+	limit := client.CallLimit()
+	if limit.Max != 40 || limit.Used < 38 || limit.Used > 39 {
+		t.Errorf("Client.CallLimit() = %v, expected about 39/40", limit)
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	setup()
+	defer teardown()
+
+	limiter := NewRateLimiter()
+	client.RateLimiter = limiter
+	other := NewClient(app, "fooshop", "efgh")
+	other.Client = client.Client
+	other.RateLimiter = limiter
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo", callLimitResponder("20/40"))
+
+	err := other.Get(context.Background(), "foo", nil, nil)
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+
+	if limit := client.CallLimit(); limit.Max != 40 {
+		t.Errorf("Client.CallLimit() = %v, expected the limit seen by the other client", limit)
+	}
+
+	if _, ok := limiter.CallLimit("othershop"); ok {
+		t.Errorf("RateLimiter.CallLimit(othershop) returned a limit for an unseen shop")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := &RateLimiter{Headroom: 5, LeakRate: 100}
+	limiter.update("fooshop", &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{CallLimitHeader: []string{"40/40"}},
+	})
+
+	// 6 calls need to drain at 100 calls per second before there is room.
+	start := time.Now()
+	err := limiter.Wait(context.Background(), "fooshop")
+	if err != nil {
+		t.Fatalf("RateLimiter.Wait() returned error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("RateLimiter.Wait() returned after %v, expected at least 50ms", elapsed)
+	}
+
+	// Shops that have not been seen yet are never throttled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = limiter.Wait(ctx, "othershop")
+	if err != nil {
+		t.Errorf("RateLimiter.Wait(othershop) returned error: %v", err)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := &RateLimiter{Headroom: 1, LeakRate: 0.001}
+	limiter.update("fooshop", &http.Response{
+		StatusCode: 429,
+		Header:     http.Header{CallLimitHeader: []string{"39/40"}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := limiter.Wait(ctx, "fooshop")
+	if err != context.DeadlineExceeded {
+		t.Errorf("RateLimiter.Wait(): expected %v, actual %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestRateLimiterQueryCostsCapped(t *testing.T) {
+	limiter := NewRateLimiter()
+	cost := &GraphQLCost{RequestedQueryCost: 1, ThrottleStatus: GraphQLThrottleStatus{MaximumAvailable: 1000}}
+
+	for i := 0; i < 2*maxQueryCosts; i++ {
+		limiter.updateGraphQL("fooshop", fmt.Sprintf(`{ order(id: "gid://shopify/Order/%d") { id } }`, i), cost)
+	}
+
+	if len(limiter.queryCosts) != maxQueryCosts {
+		t.Errorf("RateLimiter remembers %d query costs, expected %d", len(limiter.queryCosts), maxQueryCosts)
+	}
+
+	last := fmt.Sprintf(`{ order(id: "gid://shopify/Order/%d") { id } }`, 2*maxQueryCosts-1)
+	if limiter.queryCosts[last] != 1 {
+		t.Errorf("RateLimiter forgot the cost of the last query")
+	}
+}
@@ -0,0 +1,93 @@
+package goshopify
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header that asks Shopify to process a
+// request at most once, e.g. the creation of a transaction.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RequestOptions adjusts the API calls made with a context, without changing
+// the signatures of the service methods. See ContextWithRequestOptions.
+type RequestOptions struct {
+	// Headers added to the requests, replacing the ones set by the client.
+	Header http.Header
+
+	// The maximum duration of a call, including retries and rate limit
+	// waits. Zero means no timeout besides the context's own.
+	Timeout time.Duration
+
+	// The Admin API version to request instead of the client's.
+	APIVersion string
+
+	// Send the requests only once, regardless of the client's RetryPolicy.
+	DisableRetries bool
+
+	// Sent as the Idempotency-Key header of every POST request made with the
+	// context, so a POST that failed can be sent again with the same context
+	// without being processed twice. Use a new key for every operation. It
+	// does not make POST requests retried on errors other than 429 Too Many
+	// Requests.
+	IdempotencyKey string
+}
+
+type requestOptionsKey struct{}
+
+// ContextWithRequestOptions returns a context that applies the options to the
+// API calls made with it. Options already in the context are kept unless they
+// are overridden: headers are merged and non-zero fields replace the existing
+// ones.
+//
+//	ctx = goshopify.ContextWithRequestOptions(ctx, goshopify.RequestOptions{
+//	    Timeout:        5 * time.Second,
+//	    IdempotencyKey: "order-1001-refund",
+//	})
+//	err := client.Post(ctx, "admin/orders/1001/transactions.json", transaction, resource)
+func ContextWithRequestOptions(ctx context.Context, opts RequestOptions) context.Context {
+	merged := requestOptionsFrom(ctx)
+
+	if len(opts.Header) > 0 {
+		header := merged.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		for k, v := range opts.Header {
+			header[http.CanonicalHeaderKey(k)] = v
+		}
+		merged.Header = header
+	}
+	if opts.Timeout > 0 {
+		merged.Timeout = opts.Timeout
+	}
+	if opts.APIVersion != "" {
+		merged.APIVersion = opts.APIVersion
+	}
+	if opts.DisableRetries {
+		merged.DisableRetries = true
+	}
+	if opts.IdempotencyKey != "" {
+		merged.IdempotencyKey = opts.IdempotencyKey
+	}
+
+	return context.WithValue(ctx, requestOptionsKey{}, merged)
+}
+
+// Returns the request options of a context, the zero value if it has none.
+func requestOptionsFrom(ctx context.Context) RequestOptions {
+	opts, _ := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	return opts
+}
+
+// Sets the headers of the request options on a request. The idempotency key
+// is only set on POST requests.
+func (opts RequestOptions) apply(req *http.Request) {
+	for k, v := range opts.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if opts.IdempotencyKey != "" && req.Method == http.MethodPost {
+		req.Header.Set(IdempotencyKeyHeader, opts.IdempotencyKey)
+	}
+}
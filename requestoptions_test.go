@@ -0,0 +1,158 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestContextWithRequestOptionsMerge(t *testing.T) {
+	ctx := ContextWithRequestOptions(context.Background(), RequestOptions{
+		Header:     http.Header{"X-Foo": {"foo"}},
+		Timeout:    time.Second,
+		APIVersion: "2023-01",
+	})
+	ctx = ContextWithRequestOptions(ctx, RequestOptions{
+		Header:         http.Header{"x-bar": {"bar"}},
+		DisableRetries: true,
+	})
+	ctx = ContextWithAPIVersion(ctx, "2023-04")
+
+	opts := requestOptionsFrom(ctx)
+	if opts.Header.Get("X-Foo") != "foo" || opts.Header.Get("X-Bar") != "bar" {
+		t.Errorf("Header = %v, expected X-Foo and X-Bar", opts.Header)
+	}
+	if opts.Timeout != time.Second || opts.APIVersion != "2023-04" || !opts.DisableRetries {
+		t.Errorf("RequestOptions = %+v", opts)
+	}
+}
+
+func TestRequestOptionsHeaders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2023-01/orders/1/transactions.json",
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Foo") != "foo" || req.Header.Get(IdempotencyKeyHeader) != "refund-1" ||
+				req.Header.Get("User-Agent") != "custom" {
+				return httpmock.NewStringResponse(400, `{"errors": "missing headers"}`), nil
+			}
+			return httpmock.NewStringResponse(201, `{}`), nil
+		})
+
+	ctx := ContextWithRequestOptions(context.Background(), RequestOptions{
+		Header:         http.Header{"X-Foo": {"foo"}, "User-Agent": {"custom"}},
+		APIVersion:     "2023-01",
+		IdempotencyKey: "refund-1",
+	})
+
+	err := client.Post(ctx, "admin/orders/1/transactions.json", nil, nil)
+	if err != nil {
+		t.Errorf("Post returned error: %v", err)
+	}
+}
+
+func TestRequestOptionsIdempotencyKeyNotRetried(t *testing.T) {
+	retrySetup()
+	defer teardown()
+
+	calls := 0
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls,
+			httpmock.NewStringResponder(503, `{"errors": "unavailable"}`),
+			httpmock.NewStringResponder(201, `{}`)))
+
+	ctx := ContextWithRequestOptions(context.Background(), RequestOptions{IdempotencyKey: "foo-1"})
+	err := client.Post(ctx, "foo", nil, nil)
+	if !errors.As(err, new(ServerError)) || calls != 1 {
+		t.Errorf("Post with idempotency key made %d calls (err: %v), expected 1 failed call", calls, err)
+	}
+}
+
+func TestRequestOptionsIdempotencyKeyResent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var keys []string
+	fail := true
+	record := func(req *http.Request) (*http.Response, error) {
+		keys = append(keys, req.Method+" "+req.Header.Get(IdempotencyKeyHeader))
+		if req.Method == "POST" && fail {
+			fail = false
+			return httpmock.NewStringResponse(503, `{"errors": "unavailable"}`), nil
+		}
+		return httpmock.NewStringResponse(200, `{}`), nil
+	}
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo", record)
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/foo", record)
+
+	ctx := ContextWithRequestOptions(context.Background(), RequestOptions{IdempotencyKey: "foo-1"})
+	client.Get(ctx, "foo", nil, nil)
+
+	// The failed POST is sent again by the caller with the same key.
+	err := client.Post(ctx, "foo", nil, nil)
+	if !errors.As(err, new(ServerError)) {
+		t.Errorf("Post returned %v, expected a ServerError", err)
+	}
+	err = client.Post(ctx, "foo", nil, nil)
+	if err != nil {
+		t.Errorf("Post returned error: %v", err)
+	}
+
+	// A derived context keeps the key unless it sets a new one.
+	client.Post(ContextWithRequestOptions(ctx, RequestOptions{Timeout: time.Second}), "foo", nil, nil)
+	client.Post(ContextWithRequestOptions(ctx, RequestOptions{IdempotencyKey: "foo-2"}), "foo", nil, nil)
+
+	expected := []string{"GET ", "POST foo-1", "POST foo-1", "POST foo-1", "POST foo-2"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Idempotency keys sent %q, expected %q", keys, expected)
+	}
+}
+
+func TestRequestOptionsDisableRetries(t *testing.T) {
+	retrySetup()
+	defer teardown()
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls, httpmock.NewStringResponder(503, `{"errors": "unavailable"}`)))
+
+	ctx := ContextWithRequestOptions(context.Background(), RequestOptions{DisableRetries: true})
+	err := client.Get(ctx, "foo", nil, nil)
+	if !errors.As(err, new(ServerError)) || calls != 1 {
+		t.Errorf("Get made %d calls (err: %v), expected 1 failed call", calls, err)
+	}
+}
+
+func TestRequestOptionsTimeout(t *testing.T) {
+	retrySetup()
+	defer teardown()
+
+	calls := 0
+	done := make(chan struct{})
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls, func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			close(done)
+			return nil, req.Context().Err()
+		}))
+
+	ctx := ContextWithRequestOptions(context.Background(), RequestOptions{Timeout: 10 * time.Millisecond})
+	err := client.Get(ctx, "foo", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get returned %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	// The responder keeps running in the background after the call returns.
+	<-done
+
+	// The timeout applies to the call as a whole, not to every attempt.
+	if calls != 1 {
+		t.Errorf("Get made %d calls, expected 1", calls)
+	}
+}
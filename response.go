@@ -0,0 +1,62 @@
+package goshopify
+
+import (
+	"context"
+	"net/http"
+)
+
+// DeprecatedReasonHeader is the response header in which Shopify explains why
+// a request uses a deprecated endpoint or field.
+// See: https://shopify.dev/docs/api/usage/versioning#deprecation-practices
+const DeprecatedReasonHeader = "X-Shopify-API-Deprecated-Reason"
+
+// Response holds the metadata of an API response that service methods do not
+// return, such as the request ID and deprecation notices. Use
+// ContextWithResponse to capture it for a call.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+
+	// The X-Request-Id of the response, which Shopify support asks for.
+	RequestID string
+
+	// The API version that served the request.
+	APIVersion string
+
+	// The reason the request is deprecated, empty if it is not.
+	DeprecatedReason string
+
+	// The pagination parsed from the Link header, nil if the response has no
+	// valid Link header.
+	Pagination *Pagination
+}
+
+type responseKey struct{}
+
+// ContextWithResponse returns a context that makes the client fill in resp
+// with the metadata of the final response of every call made with it. When
+// multiple calls are made with the context, e.g. by an Iterate method, resp
+// holds the metadata of the last one. The metadata is filled in for
+// unsuccessful responses as well.
+//
+//	var resp goshopify.Response
+//	order, err := client.Order.Get(goshopify.ContextWithResponse(ctx, &resp), orderID, nil)
+//	log.Printf("request %s", resp.RequestID)
+func ContextWithResponse(ctx context.Context, resp *Response) context.Context {
+	return context.WithValue(ctx, responseKey{}, resp)
+}
+
+// Fills in the metadata from an HTTP response.
+func (r *Response) fill(resp *http.Response) {
+	*r = Response{
+		StatusCode:       resp.StatusCode,
+		Header:           resp.Header,
+		RequestID:        resp.Header.Get(RequestIDHeader),
+		APIVersion:       resp.Header.Get(APIVersionHeader),
+		DeprecatedReason: resp.Header.Get(DeprecatedReasonHeader),
+	}
+
+	if link := resp.Header.Get("Link"); link != "" {
+		r.Pagination, _ = extractPagination(link)
+	}
+}
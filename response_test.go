@@ -0,0 +1,73 @@
+package goshopify
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestContextWithResponse(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"orders": [{"id": 1}]}`)
+			resp.Header.Set(RequestIDHeader, "abc-123")
+			resp.Header.Set(APIVersionHeader, "2024-10")
+			resp.Header.Set(DeprecatedReasonHeader, "https://shopify.dev/changelog/foo")
+			resp.Header.Set("Link", `<https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=1&page_info=abc>; rel="next"`)
+			return resp, nil
+		})
+
+	var resp Response
+	orders, err := client.Order.List(ContextWithResponse(context.Background(), &resp), nil)
+	if err != nil {
+		t.Fatalf("Order.List returned error: %v", err)
+	}
+	if len(orders) != 1 {
+		t.Errorf("Order.List returned %d orders, expected 1", len(orders))
+	}
+
+	if resp.StatusCode != 200 || resp.RequestID != "abc-123" || resp.APIVersion != "2024-10" ||
+		resp.DeprecatedReason != "https://shopify.dev/changelog/foo" {
+		t.Errorf("Response = %+v", resp)
+	}
+
+	expectedPagination := &Pagination{NextPageOptions: &ListOptions{PageInfo: "abc", Limit: 1}}
+	if !reflect.DeepEqual(resp.Pagination, expectedPagination) {
+		t.Errorf("Response.Pagination = %#v, expected %#v", resp.Pagination, expectedPagination)
+	}
+}
+
+func TestContextWithResponseError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders/1.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(404, `{"errors": "Not Found"}`)
+			resp.Header.Set(RequestIDHeader, "abc-123")
+			return resp, nil
+		})
+
+	var resp Response
+	_, err := client.Order.Get(ContextWithResponse(context.Background(), &resp), 1, nil)
+
+	expected := NotFoundError{ResponseError{
+		Status:    404,
+		Message:   "Not Found",
+		RequestID: "abc-123",
+		Body:      []byte(`{"errors": "Not Found"}`),
+	}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Order.Get returned %#v, expected %#v", err, expected)
+	}
+
+	if resp.StatusCode != 404 || resp.RequestID != "abc-123" || resp.Pagination != nil {
+		t.Errorf("Response = %+v", resp)
+	}
+}
@@ -0,0 +1,112 @@
+package goshopify
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy describes how Client.Do retries requests that failed because of
+// rate limiting, server errors or network errors.
+//
+// Idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are retried on
+// 429s, 5xx responses and network errors. Other requests, i.e. POST, are only
+// retried on 429s since Shopify guarantees that throttled requests were not
+// processed.
+type RetryPolicy struct {
+	// The maximum number of attempts for a single request, including the
+	// first one. A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// The backoff before the first retry. The backoff doubles on every
+	// following attempt up to MaxBackoff. Some random jitter is applied to
+	// the backoff so concurrent clients do not retry in lockstep.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used by clients created with
+// NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// Returns how long to wait before the next attempt of req, which failed with
+// err on the given (1-based) attempt. The second return value is false if the
+// request should not be retried.
+func (p RetryPolicy) delay(req *http.Request, attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || req.Context().Err() != nil {
+		return 0, false
+	}
+
+	// The body has to be sent again, which is only possible if it can be
+	// rewound.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+
+	switch e := err.(type) {
+	case RateLimitError:
+		if e.RetryAfter > 0 {
+			return time.Duration(e.RetryAfter) * time.Second, true
+		}
+		return p.backoff(attempt), true
+	case ServerError:
+		if isIdempotent(req) {
+			return p.backoff(attempt), true
+		}
+	case *url.Error:
+		if isIdempotent(req) {
+			return p.backoff(attempt), true
+		}
+	}
+
+	return 0, false
+}
+
+// Exponential backoff with jitter for the given (1-based) attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	// Wait somewhere between half and the full backoff.
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// Reports whether a request can be sent again without side effects. POST
+// requests are not, even with an idempotency key, since Shopify does not
+// document support for the header on every endpoint.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// Waits for the given duration or until the context is done, whichever comes
+// first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
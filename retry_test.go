@@ -0,0 +1,154 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+// Returns a responder that replies with the given responders in order and
+// keeps replying with the last one.
+func sequenceResponder(calls *int, responders ...httpmock.Responder) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		i := *calls
+		if i >= len(responders) {
+			i = len(responders) - 1
+		}
+		*calls++
+		return responders[i](req)
+	}
+}
+
+func retrySetup() {
+	setup()
+	client.Retry = RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	cases := []struct {
+		method    string
+		responder httpmock.Responder
+		calls     int
+	}{
+		{"GET", httpmock.NewStringResponder(500, `{"errors": "oops"}`), 2},
+		{"GET", httpmock.NewStringResponder(429, `{"errors": "slow down"}`), 2},
+		{"GET", httpmock.NewErrorResponder(errors.New("connection reset")), 2},
+		{"PUT", httpmock.NewStringResponder(503, `{"errors": "unavailable"}`), 2},
+		{"GET", httpmock.NewStringResponder(502, `<html><body>502 Bad Gateway</body></html>`), 2},
+		{"POST", httpmock.NewStringResponder(429, `{"errors": "slow down"}`), 2},
+		{"POST", httpmock.NewStringResponder(500, `{"errors": "oops"}`), 1},
+		{"POST", httpmock.NewErrorResponder(errors.New("connection reset")), 1},
+		{"GET", httpmock.NewStringResponder(404, `{"errors": "Not Found"}`), 1},
+	}
+
+	for _, c := range cases {
+		retrySetup()
+
+		calls := 0
+		httpmock.RegisterResponder(c.method, "https://fooshop.myshopify.com/foo",
+			sequenceResponder(&calls, c.responder, httpmock.NewStringResponder(200, `{"foo": "bar"}`)))
+
+		err := client.CreateAndDo(context.Background(), c.method, "foo", struct{}{}, nil, nil)
+		if calls != c.calls {
+			t.Errorf("CreateAndDo(%v) made %d calls, expected %d (err: %v)", c.method, calls, c.calls, err)
+		}
+
+		teardown()
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	retrySetup()
+	defer teardown()
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls, httpmock.NewStringResponder(502, `{"errors": "bad gateway"}`)))
+
+	err := client.Get(context.Background(), "foo", nil, nil)
+	if e, ok := err.(ServerError); !ok || e.Status != 502 {
+		t.Errorf("Get(): expected ServerError with status 502, actual %#v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("Get() made %d calls, expected 3", calls)
+	}
+}
+
+func TestRetryRetryAfter(t *testing.T) {
+	retrySetup()
+	defer teardown()
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls,
+			func(req *http.Request) (*http.Response, error) {
+				resp := httpmock.NewStringResponse(429, `{"errors": "slow down"}`)
+				resp.Header.Add("Retry-After", "1.0")
+				return resp, nil
+			},
+			httpmock.NewStringResponder(200, `{}`),
+		))
+
+	start := time.Now()
+	err := client.Get(context.Background(), "foo", nil, nil)
+	if err != nil {
+		t.Errorf("Get() returned error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Get() retried after %v, expected at least 1s", elapsed)
+	}
+}
+
+func TestRetryContextCancelled(t *testing.T) {
+	retrySetup()
+	defer teardown()
+	client.Retry.MinBackoff = time.Hour
+	client.Retry.MaxBackoff = time.Hour
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/foo",
+		sequenceResponder(&calls, httpmock.NewStringResponder(500, `{"errors": "oops"}`)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := client.Get(ctx, "foo", nil, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Get(): expected %v, actual %v", context.DeadlineExceeded, err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Get() made %d calls, expected 1", calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	cases := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, c := range cases {
+		d := p.backoff(c.attempt)
+		if d < c.min || d > c.max {
+			t.Errorf("RetryPolicy.backoff(%d) = %v, expected between %v and %v", c.attempt, d, c.min, c.max)
+		}
+	}
+}
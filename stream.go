@@ -0,0 +1,111 @@
+package goshopify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StreamList performs a GET request for a page of a collection and decodes the
+// elements of the array in the given key of the response, e.g. "orders", one
+// by one while the body is read. newElement returns a value to decode an
+// element into, e.g. a new(Order), and visit is called for every decoded
+// element before the next one is read. This keeps only a single element in
+// memory instead of the whole page, and lets processing start before the body
+// has been received completely.
+//
+// visit is called while the response is still open, so the time it takes
+// counts against the timeout of the HTTP client and of the RequestOptions, and
+// keeps the connection busy.
+//
+// Streaming stops at the first error returned by visit, which is returned as
+// is. Elements visited before an error, including an error decoding the
+// response, are not rolled back. The returned Pagination contains the options
+// to retrieve the next and previous pages.
+func (c *Client) StreamList(ctx context.Context, path, key string, options interface{}, newElement func() interface{}, visit func(interface{}) error) (*Pagination, error) {
+	req, err := c.newRequest(ctx, "GET", path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := c.doDecode(req, func(body io.Reader) error {
+		return streamArray(body, key, newElement, visit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return extractPagination(headers.Get("Link"))
+}
+
+// Decodes the elements of the array in the given key of the JSON object in r
+// one by one. Other keys are skipped.
+func streamArray(r io.Reader, key string, newElement func() interface{}, visit func(interface{}) error) error {
+	decoder := json.NewDecoder(r)
+
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if token != key {
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Shopify never returns null instead of an empty array, but be
+		// lenient anyway.
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			continue
+		}
+		if token != json.Delim('[') {
+			return fmt.Errorf("expected an array in %q, got %v", key, token)
+		}
+
+		for decoder.More() {
+			element := newElement()
+			err = decoder.Decode(element)
+			if err != nil {
+				return err
+			}
+
+			err = visit(element)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = expectDelim(decoder, ']')
+		if err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v in JSON, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestStreamArray(t *testing.T) {
+	cases := []struct {
+		body     string
+		expected []int
+		err      string
+	}{
+		{`{"orders": [{"id": 1}, {"id": 2}]}`, []int{1, 2}, ""},
+		{`{"count": 2, "orders": [{"id": 1}, {"id": 2}], "extra": {"orders": [{"id": 3}]}}`, []int{1, 2}, ""},
+		{`{"orders": []}`, nil, ""},
+		{`{"orders": null}`, nil, ""},
+		{`{}`, nil, ""},
+		{`{"orders": {"id": 1}}`, nil, `expected an array in "orders"`},
+		{`{"orders": [{"id": 1}, {"id": "two"}]}`, []int{1}, "cannot unmarshal"},
+		{`{"orders": [{"id": 1}, {"id"`, []int{1}, "EOF"},
+		{`[]`, nil, "expected { in JSON"},
+	}
+
+	for _, c := range cases {
+		var visited []int
+		err := streamArray(strings.NewReader(c.body), "orders",
+			func() interface{} { return new(Order) },
+			func(o interface{}) error {
+				visited = append(visited, o.(*Order).ID)
+				return nil
+			})
+
+		if c.err == "" && err != nil {
+			t.Errorf("streamArray(%s) returned error: %v", c.body, err)
+		} else if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("streamArray(%s) returned %v, expected error %q", c.body, err, c.err)
+		}
+
+		if !reflect.DeepEqual(visited, c.expected) {
+			t.Errorf("streamArray(%s) visited %v, expected %v", c.body, visited, c.expected)
+		}
+	}
+}
+
+func TestStreamList(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=2",
+		linkResponder(200, `{"orders": [{"id": 1}, {"id": 2}]}`,
+			`<https://fooshop.myshopify.com/admin/api/2024-10/orders.json?limit=2&page_info=abc>; rel="next"`))
+
+	var visited []int
+	pagination, err := client.StreamList(context.Background(), "admin/orders.json", "orders", ListOptions{Limit: 2},
+		func() interface{} { return new(Order) },
+		func(o interface{}) error {
+			visited = append(visited, o.(*Order).ID)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("StreamList returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(visited, []int{1, 2}) {
+		t.Errorf("StreamList visited %v, expected [1 2]", visited)
+	}
+
+	expected := &Pagination{NextPageOptions: &ListOptions{PageInfo: "abc", Limit: 2}}
+	if !reflect.DeepEqual(pagination, expected) {
+		t.Errorf("StreamList returned pagination %#v, expected %#v", pagination, expected)
+	}
+}
+
+func TestStreamListStop(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json",
+		httpmock.NewStringResponder(200, `{"orders": [{"id": 1}, {"id": 2}]}`))
+
+	calls := 0
+	_, err := client.StreamList(context.Background(), "admin/orders.json", "orders", nil,
+		func() interface{} { return new(Order) },
+		func(o interface{}) error {
+			calls++
+			return ErrStopIteration
+		})
+	if err != ErrStopIteration || calls != 1 {
+		t.Errorf("StreamList returned %v after %d calls, expected ErrStopIteration after 1", err, calls)
+	}
+}
+
+func TestStreamListError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.Retry = RetryPolicy{}
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json",
+		httpmock.NewStringResponder(404, `{"errors": "Not Found"}`))
+
+	_, err := client.StreamList(context.Background(), "admin/orders.json", "orders", nil,
+		func() interface{} { return new(Order) },
+		func(o interface{}) error {
+			t.Error("Unexpected element")
+			return nil
+		})
+	if !errors.As(err, new(NotFoundError)) {
+		t.Errorf("StreamList returned %#v, expected a NotFoundError", err)
+	}
+}
+
+func TestStreamListIncremental(t *testing.T) {
+	setup()
+	defer teardown()
+
+	reader, writer := io.Pipe()
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, "")
+			resp.Body = reader
+			return resp, nil
+		})
+
+	go io.WriteString(writer, `{"orders": [{"id": 1},`)
+
+	var visited []int
+	_, err := client.StreamList(context.Background(), "admin/orders.json", "orders", nil,
+		func() interface{} { return new(Order) },
+		func(o interface{}) error {
+			visited = append(visited, o.(*Order).ID)
+			if len(visited) == 1 {
+				// The rest of the body is only sent once the first
+				// order has been visited.
+				go func() {
+					io.WriteString(writer, ` {"id": 2}]}`)
+					writer.Close()
+				}()
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("StreamList returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(visited, []int{1, 2}) {
+		t.Errorf("StreamList visited %v, expected [1 2]", visited)
+	}
+}
+
+// A body that fails once the request is done, like the body of a real
+// response whose request timed out.
+type requestBody struct {
+	req *http.Request
+	r   io.Reader
+}
+
+func (b requestBody) Read(p []byte) (int, error) {
+	if err := b.req.Context().Err(); err != nil {
+		return 0, err
+	}
+	return b.r.Read(p)
+}
+
+func (b requestBody) Close() error {
+	return nil
+}
+
+func TestIterateSlowCallback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/orders.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, "")
+			resp.Body = requestBody{req, iotest.OneByteReader(strings.NewReader(`{"orders": [{"id": 1}, {"id": 2}]}`))}
+			return resp, nil
+		})
+
+	ctx := ContextWithRequestOptions(context.Background(), RequestOptions{Timeout: 20 * time.Millisecond})
+	slow := func(o *Order) error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}
+
+	// Iterate reads the whole page before calling fn, so the timeout only
+	// covers the request.
+	err := client.Order.Iterate(ctx, nil, slow)
+	if err != nil {
+		t.Errorf("Order.Iterate returned error: %v", err)
+	}
+
+	// Stream calls fn while the response is read, so fn counts against it.
+	err = client.Order.Stream(ctx, nil, slow)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Order.Stream returned %v, expected %v", err, context.DeadlineExceeded)
+	}
+}
@@ -15,6 +15,7 @@ const variantsBasePath = "admin/variants"
 // See https://help.shopify.com/api/reference/product_variant
 type VariantService interface {
 	List(context.Context, int, interface{}) ([]*Variant, error)
+	ListWithPagination(context.Context, int, interface{}) ([]*Variant, *Pagination, error)
 	Count(context.Context, int, interface{}) (int, error)
 	Get(context.Context, int, interface{}) (*Variant, error)
 	Create(context.Context, int, *Variant) (*Variant, error)
@@ -74,6 +75,15 @@ func (s *VariantServiceOp) List(ctx context.Context, productID int, options inte
 	return resource.Variants, err
 }
 
+// ListWithPagination lists variants and returns the pagination to retrieve
+// the next and previous pages.
+func (s *VariantServiceOp) ListWithPagination(ctx context.Context, productID int, options interface{}) ([]*Variant, *Pagination, error) {
+	path := fmt.Sprintf("%s/%d/variants.json", productsBasePath, productID)
+	resource := new(VariantsResource)
+	pagination, err := s.client.ListWithPagination(ctx, path, resource, options)
+	return resource.Variants, pagination, err
+}
+
 // Count variants
 func (s *VariantServiceOp) Count(ctx context.Context, productID int, options interface{}) (int, error) {
 	path := fmt.Sprintf("%s/%d/variants/count.json", productsBasePath, productID)
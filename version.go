@@ -0,0 +1,62 @@
+package goshopify
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultAPIVersion is the Admin API version used by clients that are created
+// without the WithAPIVersion option.
+// See: https://help.shopify.com/api/versioning
+const DefaultAPIVersion = "2024-10"
+
+// APIVersionHeader is the response header in which Shopify reports the API
+// version that served the request. This differs from the requested version
+// when Shopify falls back to another version, e.g. because the requested one
+// is no longer supported.
+const APIVersionHeader = "X-Shopify-API-Version"
+
+// ContextWithAPIVersion returns a context that overrides the client's API
+// version for the requests made with it. It is a shorthand for
+// ContextWithRequestOptions with only the APIVersion set.
+func ContextWithAPIVersion(ctx context.Context, version string) context.Context {
+	return ContextWithRequestOptions(ctx, RequestOptions{APIVersion: version})
+}
+
+// APIVersion returns the Admin API version requested by the client.
+func (c *Client) APIVersion() string {
+	return c.apiVersion
+}
+
+// ServedAPIVersion returns the API version that Shopify reported in the
+// X-Shopify-API-Version header of the last response. Compare it with
+// APIVersion to detect that Shopify silently fell back to another version.
+func (c *Client) ServedAPIVersion() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.servedAPIVersion
+}
+
+// Returns the API version to use for requests made with the given context.
+func (c *Client) apiVersionFor(ctx context.Context) string {
+	if version := requestOptionsFrom(ctx).APIVersion; version != "" {
+		return version
+	}
+	return c.apiVersion
+}
+
+// Injects the API version into an admin path, e.g. admin/orders.json becomes
+// admin/api/2024-10/orders.json. Paths that are already versioned, OAuth paths
+// and paths outside of the admin are returned as is.
+func versionedPath(path, version string) string {
+	trimmed := strings.TrimPrefix(path, "/")
+	if version == "" ||
+		!strings.HasPrefix(trimmed, "admin/") ||
+		strings.HasPrefix(trimmed, "admin/api/") ||
+		strings.HasPrefix(trimmed, "admin/oauth/") {
+		return path
+	}
+
+	prefix := path[:len(path)-len(trimmed)]
+	return prefix + "admin/api/" + version + "/" + strings.TrimPrefix(trimmed, "admin/")
+}
@@ -0,0 +1,96 @@
+package goshopify
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestVersionedPath(t *testing.T) {
+	cases := []struct {
+		path     string
+		version  string
+		expected string
+	}{
+		{"admin/orders.json", "2024-10", "admin/api/2024-10/orders.json"},
+		{"/admin/products/1/metafields.json", "2024-10", "/admin/api/2024-10/products/1/metafields.json"},
+		{"admin/api/2023-01/orders.json", "2024-10", "admin/api/2023-01/orders.json"},
+		{"admin/oauth/access_token", "2024-10", "admin/oauth/access_token"},
+		{"foo/admin/orders.json", "2024-10", "foo/admin/orders.json"},
+		{"admin/orders.json", "", "admin/orders.json"},
+	}
+
+	for _, c := range cases {
+		actual := versionedPath(c.path, c.version)
+		if actual != c.expected {
+			t.Errorf("versionedPath(%q, %q) = %q, expected %q", c.path, c.version, actual, c.expected)
+		}
+	}
+}
+
+func TestNewClientWithAPIVersion(t *testing.T) {
+	testClient := NewClient(app, "fooshop", "abcd")
+	if testClient.APIVersion() != DefaultAPIVersion {
+		t.Errorf("NewClient APIVersion = %v, expected %v", testClient.APIVersion(), DefaultAPIVersion)
+	}
+
+	testClient = NewClient(app, "fooshop", "abcd", WithAPIVersion("2023-07"))
+	if testClient.APIVersion() != "2023-07" {
+		t.Errorf("NewClient APIVersion = %v, expected %v", testClient.APIVersion(), "2023-07")
+	}
+
+	req, err := testClient.NewRequest("GET", "admin/shop.json", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+
+	expected := "https://fooshop.myshopify.com/admin/api/2023-07/shop.json"
+	if req.URL.String() != expected {
+		t.Errorf("NewRequest URL = %v, expected %v", req.URL, expected)
+	}
+}
+
+func TestContextWithAPIVersion(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2023-01/shop.json",
+		httpmock.NewStringResponder(200, `{"shop": {"id": 1}}`))
+
+	ctx := ContextWithAPIVersion(context.Background(), "2023-01")
+	shop, err := client.Shop.Get(ctx, nil)
+	if err != nil {
+		t.Fatalf("Shop.Get returned error: %v", err)
+	}
+
+	if shop.ID != 1 {
+		t.Errorf("Shop.ID returned %v, expected 1", shop.ID)
+	}
+}
+
+func TestServedAPIVersion(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/shop.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"shop": {"id": 1}}`)
+			resp.Header.Set(APIVersionHeader, "2025-01")
+			return resp, nil
+		})
+
+	if client.ServedAPIVersion() != "" {
+		t.Errorf("ServedAPIVersion = %v before any request, expected empty", client.ServedAPIVersion())
+	}
+
+	_, err := client.Shop.Get(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Shop.Get returned error: %v", err)
+	}
+
+	if client.ServedAPIVersion() != "2025-01" {
+		t.Errorf("ServedAPIVersion = %v, expected %v", client.ServedAPIVersion(), "2025-01")
+	}
+}
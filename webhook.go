@@ -13,11 +13,15 @@ const webhooksBasePath = "admin/webhooks"
 // See: https://help.shopify.com/api/reference/webhook
 type WebhookService interface {
 	List(context.Context, interface{}) ([]Webhook, error)
+	ListWithPagination(context.Context, interface{}) ([]Webhook, *Pagination, error)
 	Count(context.Context, interface{}) (int, error)
 	Get(context.Context, int, interface{}) (*Webhook, error)
 	Create(context.Context, Webhook) (*Webhook, error)
 	Update(context.Context, Webhook) (*Webhook, error)
 	Delete(context.Context, int) error
+	Plan(context.Context, []Webhook) (*WebhookPlan, error)
+	Apply(context.Context, *WebhookPlan) error
+	Reconcile(context.Context, []Webhook) (*WebhookPlan, error)
 }
 
 // WebhookServiceOp handles communication with the webhook-related methods of
@@ -62,6 +66,15 @@ func (s *WebhookServiceOp) List(ctx context.Context, options interface{}) ([]Web
 	return resource.Webhooks, err
 }
 
+// ListWithPagination lists webhooks and returns the pagination to retrieve
+// the next and previous pages.
+func (s *WebhookServiceOp) ListWithPagination(ctx context.Context, options interface{}) ([]Webhook, *Pagination, error) {
+	path := fmt.Sprintf("%s.json", webhooksBasePath)
+	resource := new(WebhooksResource)
+	pagination, err := s.client.ListWithPagination(ctx, path, resource, options)
+	return resource.Webhooks, pagination, err
+}
+
 // Count webhooks
 func (s *WebhookServiceOp) Count(ctx context.Context, options interface{}) (int, error) {
 	path := fmt.Sprintf("%s/count.json", webhooksBasePath)
@@ -76,8 +89,12 @@ func (s *WebhookServiceOp) Get(ctx context.Context, webhookdID int, options inte
 	return resource.Webhook, err
 }
 
-// Create a new webhook
+// Create a new webhook. The webhook is validated before the request is made,
+// see ErrUnknownWebhookTopic and ErrInvalidWebhookFormat.
 func (s *WebhookServiceOp) Create(ctx context.Context, webhook Webhook) (*Webhook, error) {
+	if err := validateWebhook(webhook, false); err != nil {
+		return nil, err
+	}
 	path := fmt.Sprintf("%s.json", webhooksBasePath)
 	wrappedData := WebhookResource{Webhook: &webhook}
 	resource := new(WebhookResource)
@@ -85,8 +102,12 @@ func (s *WebhookServiceOp) Create(ctx context.Context, webhook Webhook) (*Webhoo
 	return resource.Webhook, err
 }
 
-// Update an existing webhook.
+// Update an existing webhook. The webhook is validated like in Create, but
+// its topic can be left empty.
 func (s *WebhookServiceOp) Update(ctx context.Context, webhook Webhook) (*Webhook, error) {
+	if err := validateWebhook(webhook, true); err != nil {
+		return nil, err
+	}
 	path := fmt.Sprintf("%s/%d.json", webhooksBasePath, webhook.ID)
 	wrappedData := WebhookResource{Webhook: &webhook}
 	resource := new(WebhookResource)
@@ -0,0 +1,127 @@
+package goshopify
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WebhookDedupStore keeps track of the webhooks that were processed, keyed by
+// their X-Shopify-Webhook-Id, so that a WebhookHandler dispatches a webhook
+// only once even though Shopify delivers webhooks at least once. A store can
+// be shared by handlers across processes, e.g. one backed by Redis.
+//
+// A webhook is reserved while it is processed and completed once it was
+// processed, so a duplicate that arrives while the webhook is processed is not
+// acknowledged before the processing succeeded.
+type WebhookDedupStore interface {
+	// Reserve marks a webhook as being processed if it is not reserved yet.
+	// It returns WebhookReserved if it did, in which case the webhook must be
+	// processed and then completed or released. Otherwise it returns the
+	// state of the existing reservation and the webhook must not be
+	// processed.
+	Reserve(ctx context.Context, webhookID string) (WebhookDedupState, error)
+
+	// Complete marks a reserved webhook as processed.
+	Complete(ctx context.Context, webhookID string) error
+
+	// Release removes the reservation of a webhook that failed to be
+	// processed, so it is processed when Shopify delivers it again.
+	Release(ctx context.Context, webhookID string) error
+}
+
+// WebhookDedupState is the state of a webhook in a WebhookDedupStore.
+type WebhookDedupState int
+
+const (
+	// WebhookReserved means the webhook was not seen before and is now
+	// reserved by the caller.
+	WebhookReserved WebhookDedupState = iota
+
+	// WebhookInProgress means the webhook is being processed.
+	WebhookInProgress
+
+	// WebhookProcessed means the webhook was processed.
+	WebhookProcessed
+)
+
+// MemoryDedupStore is a WebhookDedupStore that keeps reservations in memory
+// for a fixed duration. It is safe for concurrent use, but only deduplicates
+// the webhooks received by a single process.
+type MemoryDedupStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]dedupEntry
+	nextSweep time.Time
+}
+
+type dedupEntry struct {
+	expires time.Time
+	done    bool
+}
+
+// NewMemoryDedupStore returns a MemoryDedupStore that keeps reservations for
+// the given duration. Shopify retries a webhook for up to 48 hours, but most
+// duplicates arrive within minutes.
+func NewMemoryDedupStore(ttl time.Duration) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		ttl:     ttl,
+		entries: make(map[string]dedupEntry),
+	}
+}
+
+// Reserve implements WebhookDedupStore.
+func (s *MemoryDedupStore) Reserve(ctx context.Context, webhookID string) (WebhookDedupState, error) {
+	return s.reserve(webhookID, time.Now()), nil
+}
+
+// Complete implements WebhookDedupStore. The webhook is kept for the TTL of
+// the store from when it was completed.
+func (s *MemoryDedupStore) Complete(ctx context.Context, webhookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[webhookID] = dedupEntry{expires: time.Now().Add(s.ttl), done: true}
+	return nil
+}
+
+// Release implements WebhookDedupStore.
+func (s *MemoryDedupStore) Release(ctx context.Context, webhookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, webhookID)
+	return nil
+}
+
+// Len returns the number of reservations, including expired ones that were
+// not removed yet.
+func (s *MemoryDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func (s *MemoryDedupStore) reserve(webhookID string, now time.Time) WebhookDedupState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Remove the expired reservations at most once per TTL, so the store
+	// doesn't grow without bound.
+	if !now.Before(s.nextSweep) {
+		for id, entry := range s.entries {
+			if !now.Before(entry.expires) {
+				delete(s.entries, id)
+			}
+		}
+		s.nextSweep = now.Add(s.ttl)
+	}
+
+	if entry, ok := s.entries[webhookID]; ok && now.Before(entry.expires) {
+		if entry.done {
+			return WebhookProcessed
+		}
+		return WebhookInProgress
+	}
+	s.entries[webhookID] = dedupEntry{expires: now.Add(s.ttl)}
+	return WebhookReserved
+}
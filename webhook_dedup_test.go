@@ -0,0 +1,53 @@
+package goshopify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryDedupStore(t *testing.T) {
+	store := NewMemoryDedupStore(time.Minute)
+	now := time.Now()
+
+	cases := []struct {
+		id       string
+		at       time.Duration
+		expected WebhookDedupState
+	}{
+		{"a", 0, WebhookReserved},
+		{"a", 30 * time.Second, WebhookInProgress},
+		{"b", 30 * time.Second, WebhookReserved},
+		{"a", time.Minute, WebhookReserved},
+	}
+	for _, c := range cases {
+		if state := store.reserve(c.id, now.Add(c.at)); state != c.expected {
+			t.Errorf("MemoryDedupStore.reserve(%s) at %v = %v, expected %v", c.id, c.at, state, c.expected)
+		}
+	}
+
+	// The expired reservation of b is swept on the next reserve after the TTL.
+	store.reserve("c", now.Add(3*time.Minute))
+	if store.Len() != 1 {
+		t.Errorf("MemoryDedupStore.Len() = %d, expected 1", store.Len())
+	}
+
+	ctx := context.Background()
+	store.Release(ctx, "c")
+	state, err := store.Reserve(ctx, "c")
+	if err != nil {
+		t.Fatalf("MemoryDedupStore.Reserve(): %v", err)
+	}
+	if state != WebhookReserved {
+		t.Errorf("MemoryDedupStore.Reserve(c) = %v after Release, expected %v", state, WebhookReserved)
+	}
+
+	store.Complete(ctx, "c")
+	state, err = store.Reserve(ctx, "c")
+	if err != nil {
+		t.Fatalf("MemoryDedupStore.Reserve(): %v", err)
+	}
+	if state != WebhookProcessed {
+		t.Errorf("MemoryDedupStore.Reserve(c) = %v after Complete, expected %v", state, WebhookProcessed)
+	}
+}
@@ -0,0 +1,207 @@
+package goshopify
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Headers that Shopify sends with every webhook.
+const (
+	WebhookTopicHeader      = "X-Shopify-Topic"
+	WebhookShopDomainHeader = "X-Shopify-Shop-Domain"
+	WebhookIDHeader         = "X-Shopify-Webhook-Id"
+	WebhookAPIVersionHeader = "X-Shopify-API-Version"
+)
+
+// WebhookDelivery is a webhook received from Shopify.
+type WebhookDelivery struct {
+	Topic      WebhookTopic
+	ShopDomain string
+
+	// The ID of the delivery, which is the same when Shopify retries it.
+	WebhookID string
+
+	// The API version of the payload.
+	APIVersion string
+
+	// The raw payload.
+	Body []byte
+}
+
+// WebhookHandler is an http.Handler that receives webhooks from Shopify. It
+// verifies their HMAC, decodes their payload and dispatches them to the
+// callback registered for their topic.
+//
+// Webhooks that fail verification are rejected with 401 Unauthorized.
+// Webhooks without a callback for their topic are acknowledged without being
+// dispatched. If the callback returns an error, the handler responds with 500
+// Internal Server Error so Shopify delivers the webhook again later. Set a
+// DedupStore to not dispatch the same webhook twice. A duplicate of a webhook
+// that is still being processed is rejected with 409 Conflict, so it is
+// delivered again if the processing fails.
+//
+//	handler := goshopify.NewWebhookHandler(app)
+//	handler.HandleOrder(goshopify.TopicOrdersCreate, func(ctx context.Context, d *goshopify.WebhookDelivery, order *goshopify.Order) error {
+//	    return fulfil(ctx, d.ShopDomain, order)
+//	})
+//	http.Handle("/webhooks", handler)
+type WebhookHandler struct {
+	// DedupStore, if set, deduplicates webhooks by their X-Shopify-Webhook-Id.
+	// Duplicates of processed webhooks are acknowledged without being
+	// dispatched again.
+	DedupStore WebhookDedupStore
+
+	app App
+
+	mu        sync.RWMutex
+	callbacks map[WebhookTopic]webhookCallback
+}
+
+type webhookCallback struct {
+	newPayload func() interface{}
+	call       func(context.Context, *WebhookDelivery, interface{}) error
+}
+
+// NewWebhookHandler returns a WebhookHandler that verifies webhooks with the
+// secrets of the app.
+func NewWebhookHandler(app App) *WebhookHandler {
+	return &WebhookHandler{
+		app:       app,
+		callbacks: make(map[WebhookTopic]webhookCallback),
+	}
+}
+
+// Handle registers the callback for a topic, replacing the existing one. The
+// payload is decoded into the value returned by newPayload, which must be a
+// pointer, and passed to the callback. If newPayload is nil, the payload is
+// decoded into the type registered for the topic, see WebhookPayloadType.
+func (h *WebhookHandler) Handle(topic WebhookTopic, newPayload func() interface{}, fn func(context.Context, *WebhookDelivery, interface{}) error) {
+	if newPayload == nil {
+		newPayload = func() interface{} {
+			if payload := NewWebhookPayload(topic); payload != nil {
+				return payload
+			}
+			return new(map[string]interface{})
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callbacks[topic] = webhookCallback{newPayload: newPayload, call: fn}
+}
+
+// HandleOrder registers a callback for a topic with an Order payload, e.g.
+// orders/create or orders/paid.
+func (h *WebhookHandler) HandleOrder(topic WebhookTopic, fn func(context.Context, *WebhookDelivery, *Order) error) {
+	h.Handle(topic, func() interface{} { return new(Order) }, func(ctx context.Context, d *WebhookDelivery, v interface{}) error {
+		return fn(ctx, d, v.(*Order))
+	})
+}
+
+// HandleProduct registers a callback for a topic with a Product payload, e.g.
+// products/update.
+func (h *WebhookHandler) HandleProduct(topic WebhookTopic, fn func(context.Context, *WebhookDelivery, *Product) error) {
+	h.Handle(topic, func() interface{} { return new(Product) }, func(ctx context.Context, d *WebhookDelivery, v interface{}) error {
+		return fn(ctx, d, v.(*Product))
+	})
+}
+
+// HandleCustomer registers a callback for a topic with a Customer payload,
+// e.g. customers/create.
+func (h *WebhookHandler) HandleCustomer(topic WebhookTopic, fn func(context.Context, *WebhookDelivery, *Customer) error) {
+	h.Handle(topic, func() interface{} { return new(Customer) }, func(ctx context.Context, d *WebhookDelivery, v interface{}) error {
+		return fn(ctx, d, v.(*Customer))
+	})
+}
+
+// HandleShop registers a callback for a topic with a Shop payload, e.g.
+// shop/update.
+func (h *WebhookHandler) HandleShop(topic WebhookTopic, fn func(context.Context, *WebhookDelivery, *Shop) error) {
+	h.Handle(topic, func() interface{} { return new(Shop) }, func(ctx context.Context, d *WebhookDelivery, v interface{}) error {
+		return fn(ctx, d, v.(*Shop))
+	})
+}
+
+// ServeHTTP verifies, decodes and dispatches a webhook.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.app.VerifyWebhookRequest(r) {
+		http.Error(w, "Invalid Signature", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	delivery := &WebhookDelivery{
+		Topic:      WebhookTopic(r.Header.Get(WebhookTopicHeader)),
+		ShopDomain: r.Header.Get(WebhookShopDomainHeader),
+		WebhookID:  r.Header.Get(WebhookIDHeader),
+		APIVersion: r.Header.Get(WebhookAPIVersionHeader),
+		Body:       body,
+	}
+	if delivery.Topic == "" {
+		http.Error(w, "Missing "+WebhookTopicHeader, http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	callback, ok := h.callbacks[delivery.Topic]
+	h.mu.RUnlock()
+	if !ok {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	payload := callback.newPayload()
+	if err := json.Unmarshal(body, payload); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	dedup := h.DedupStore != nil && delivery.WebhookID != ""
+	if dedup {
+		state, err := h.DedupStore.Reserve(r.Context(), delivery.WebhookID)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		switch state {
+		case WebhookProcessed:
+			w.WriteHeader(http.StatusOK)
+			return
+		case WebhookInProgress:
+			// The first delivery may still fail, so Shopify must deliver
+			// the webhook again.
+			http.Error(w, "Webhook in progress", http.StatusConflict)
+			return
+		}
+	}
+
+	if err := callback.call(r.Context(), delivery, payload); err != nil {
+		if dedup {
+			// Shopify delivers the webhook again, which must not be
+			// discarded as a duplicate.
+			h.DedupStore.Release(r.Context(), delivery.WebhookID)
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if dedup {
+		h.DedupStore.Complete(r.Context(), delivery.WebhookID)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,219 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func webhookRequest(topic, body, secret string) *http.Request {
+	req := httptest.NewRequest("POST", "https://example.com/webhooks", strings.NewReader(body))
+	req.Header.Set(WebhookHmacHeader, webhookHmac(secret, body))
+	req.Header.Set(WebhookTopicHeader, topic)
+	req.Header.Set(WebhookShopDomainHeader, "fooshop.myshopify.com")
+	req.Header.Set(WebhookIDHeader, "b54557e4-bdd9-4b37-8a5f-bf7d70bcd043")
+	req.Header.Set(WebhookAPIVersionHeader, "2024-10")
+	return req
+}
+
+func TestWebhookHandlerDispatch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := NewWebhookHandler(app)
+
+	var order *Order
+	var delivery *WebhookDelivery
+	handler.HandleOrder("orders/create", func(ctx context.Context, d *WebhookDelivery, o *Order) error {
+		delivery = d
+		order = o
+		return nil
+	})
+
+	var product *Product
+	handler.HandleProduct("products/update", func(ctx context.Context, d *WebhookDelivery, p *Product) error {
+		product = p
+		return nil
+	})
+
+	var customer *Customer
+	handler.HandleCustomer("customers/create", func(ctx context.Context, d *WebhookDelivery, c *Customer) error {
+		customer = c
+		return nil
+	})
+
+	cases := []struct {
+		topic string
+		body  string
+	}{
+		{"orders/create", `{"id":1,"email":"john@example.com"}`},
+		{"products/update", `{"id":2,"title":"Widget"}`},
+		{"customers/create", `{"id":3,"first_name":"John"}`},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, webhookRequest(c.topic, c.body, "hush"))
+		if rec.Code != http.StatusOK {
+			t.Errorf("WebhookHandler %s: status = %d, expected %d", c.topic, rec.Code, http.StatusOK)
+		}
+	}
+
+	if order == nil || order.ID != 1 || order.Email != "john@example.com" {
+		t.Errorf("WebhookHandler order = %+v, expected ID 1", order)
+	}
+	if product == nil || product.ID != 2 || product.Title != "Widget" {
+		t.Errorf("WebhookHandler product = %+v, expected ID 2", product)
+	}
+	if customer == nil || customer.ID != 3 || customer.FirstName != "John" {
+		t.Errorf("WebhookHandler customer = %+v, expected ID 3", customer)
+	}
+
+	expected := WebhookDelivery{
+		Topic:      "orders/create",
+		ShopDomain: "fooshop.myshopify.com",
+		WebhookID:  "b54557e4-bdd9-4b37-8a5f-bf7d70bcd043",
+		APIVersion: "2024-10",
+		Body:       []byte(`{"id":1,"email":"john@example.com"}`),
+	}
+	if delivery == nil || delivery.Topic != expected.Topic || delivery.ShopDomain != expected.ShopDomain ||
+		delivery.WebhookID != expected.WebhookID || delivery.APIVersion != expected.APIVersion ||
+		string(delivery.Body) != string(expected.Body) {
+		t.Errorf("WebhookHandler delivery = %+v, expected %+v", delivery, expected)
+	}
+}
+
+func TestWebhookHandlerStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := NewWebhookHandler(app)
+	handler.HandleOrder("orders/paid", func(ctx context.Context, d *WebhookDelivery, o *Order) error {
+		if o.ID == 0 {
+			return errors.New("no order")
+		}
+		return nil
+	})
+
+	cases := []struct {
+		description string
+		req         *http.Request
+		expected    int
+	}{
+		{"valid", webhookRequest("orders/paid", `{"id":1}`, "hush"), http.StatusOK},
+		{"invalid signature", webhookRequest("orders/paid", `{"id":1}`, "other"), http.StatusUnauthorized},
+		{"unhandled topic", webhookRequest("orders/cancelled", `{"id":1}`, "hush"), http.StatusOK},
+		{"missing topic", webhookRequest("", `{"id":1}`, "hush"), http.StatusBadRequest},
+		{"invalid payload", webhookRequest("orders/paid", `{"id":"one"}`, "hush"), http.StatusBadRequest},
+		{"callback error", webhookRequest("orders/paid", `{}`, "hush"), http.StatusInternalServerError},
+		{"wrong method", httptest.NewRequest("GET", "https://example.com/webhooks", nil), http.StatusMethodNotAllowed},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, c.req)
+		if rec.Code != c.expected {
+			t.Errorf("WebhookHandler %s: status = %d, expected %d", c.description, rec.Code, c.expected)
+		}
+	}
+}
+
+func TestWebhookHandlerDedup(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := NewWebhookHandler(app)
+	handler.DedupStore = NewMemoryDedupStore(time.Hour)
+
+	calls := 0
+	fail := true
+	handler.HandleOrder("orders/paid", func(ctx context.Context, d *WebhookDelivery, o *Order) error {
+		calls++
+		if fail {
+			fail = false
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	expected := []int{
+		http.StatusInternalServerError, // failed, released
+		http.StatusOK,                  // retried by Shopify
+		http.StatusOK,                  // duplicate, not dispatched
+	}
+	for i, status := range expected {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, webhookRequest("orders/paid", `{"id":1}`, "hush"))
+		if rec.Code != status {
+			t.Errorf("WebhookHandler delivery %d: status = %d, expected %d", i, rec.Code, status)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("WebhookHandler callback called %d times, expected 2", calls)
+	}
+}
+
+func TestWebhookHandlerDedupInProgress(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := NewWebhookHandler(app)
+	handler.DedupStore = NewMemoryDedupStore(time.Hour)
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	handler.HandleOrder("orders/paid", func(ctx context.Context, d *WebhookDelivery, o *Order) error {
+		close(started)
+		<-finish
+		return errors.New("temporary failure")
+	})
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(first, webhookRequest("orders/paid", `{"id":1}`, "hush"))
+		close(done)
+	}()
+	<-started
+
+	// The first delivery may still fail, so the duplicate is not acknowledged.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, webhookRequest("orders/paid", `{"id":1}`, "hush"))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("WebhookHandler duplicate: status = %d, expected %d", rec.Code, http.StatusConflict)
+	}
+
+	close(finish)
+	<-done
+	if first.Code != http.StatusInternalServerError {
+		t.Errorf("WebhookHandler first delivery: status = %d, expected %d", first.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestWebhookHandlerRegisteredPayload(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := NewWebhookHandler(app)
+
+	var payload interface{}
+	handler.Handle(TopicOrdersPaid, nil, func(ctx context.Context, d *WebhookDelivery, v interface{}) error {
+		payload = v
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, webhookRequest(string(TopicOrdersPaid), `{"id":1}`, "hush"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("WebhookHandler: status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+
+	if order, ok := payload.(*Order); !ok || order.ID != 1 {
+		t.Errorf("WebhookHandler payload = %#v, expected *Order with ID 1", payload)
+	}
+}
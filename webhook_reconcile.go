@@ -0,0 +1,209 @@
+package goshopify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WebhookAction is a change that a WebhookPlan makes to a subscription.
+type WebhookAction string
+
+const (
+	WebhookActionCreate WebhookAction = "create"
+	WebhookActionUpdate WebhookAction = "update"
+	WebhookActionDelete WebhookAction = "delete"
+)
+
+// WebhookChange is a single change of a WebhookPlan.
+type WebhookChange struct {
+	Action WebhookAction
+
+	// The subscription after the change. For deletes, the subscription that
+	// is deleted.
+	Webhook Webhook
+
+	// The subscription before an update.
+	Current *Webhook
+}
+
+// String describes the change, e.g. "create orders/create https://example.com/webhooks (json)".
+func (c WebhookChange) String() string {
+	s := fmt.Sprintf("%s %s %s (%s)", c.Action, c.Webhook.Topic, c.Webhook.Address, webhookFormat(c.Webhook))
+	if c.Action != WebhookActionCreate {
+		s += fmt.Sprintf(" #%d", c.Webhook.ID)
+	}
+	return s
+}
+
+// WebhookPlan is the set of changes that converge the webhook subscriptions
+// of a shop to the desired ones. See WebhookService.Plan.
+type WebhookPlan struct {
+	Changes []WebhookChange
+}
+
+// Empty reports whether the subscriptions already match the desired ones.
+func (p *WebhookPlan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String lists the changes, one per line, for a dry-run.
+func (p *WebhookPlan) String() string {
+	if p.Empty() {
+		return "no changes\n"
+	}
+	var b strings.Builder
+	for _, c := range p.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Plan compares the desired webhook subscriptions with the existing ones and
+// returns the changes that converge them, without making any. Subscriptions
+// are identified by their topic and address. An existing subscription is
+// updated when its format, fields or metafield namespaces differ, and deleted
+// when it is not desired, as are duplicates of a desired subscription.
+//
+// The desired subscriptions must not contain the same topic and address
+// twice, and are validated like in Create. An empty format means json.
+func (s *WebhookServiceOp) Plan(ctx context.Context, desired []Webhook) (*WebhookPlan, error) {
+	wanted := make(map[webhookKey]Webhook, len(desired))
+	for _, w := range desired {
+		if err := validateWebhook(w, false); err != nil {
+			return nil, err
+		}
+		key := webhookKeyOf(w)
+		if _, ok := wanted[key]; ok {
+			return nil, fmt.Errorf("duplicate webhook %s %s", w.Topic, w.Address)
+		}
+		wanted[key] = w
+	}
+
+	var existing []Webhook
+	path := fmt.Sprintf("%s.json", webhooksBasePath)
+	newElement := func() interface{} { return new(Webhook) }
+	err := s.client.iterate(ctx, path, "webhooks", ListOptions{Limit: 250}, newElement, func(w interface{}) error {
+		existing = append(existing, *w.(*Webhook))
+		return nil
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := new(WebhookPlan)
+	seen := make(map[webhookKey]bool, len(existing))
+	for i := range existing {
+		current := existing[i]
+		key := webhookKeyOf(current)
+
+		want, ok := wanted[key]
+		if !ok || seen[key] {
+			plan.Changes = append(plan.Changes, WebhookChange{Action: WebhookActionDelete, Webhook: current})
+			continue
+		}
+		seen[key] = true
+
+		if !webhooksEqual(want, current) {
+			want.ID = current.ID
+			plan.Changes = append(plan.Changes, WebhookChange{Action: WebhookActionUpdate, Webhook: want, Current: &current})
+		}
+	}
+
+	for _, w := range desired {
+		if !seen[webhookKeyOf(w)] {
+			w.ID = 0
+			plan.Changes = append(plan.Changes, WebhookChange{Action: WebhookActionCreate, Webhook: w})
+		}
+	}
+
+	// Create and update before deleting, so no events are missed while a
+	// subscription moves to a new address.
+	order := map[WebhookAction]int{WebhookActionCreate: 0, WebhookActionUpdate: 1, WebhookActionDelete: 2}
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.Action != b.Action {
+			return order[a.Action] < order[b.Action]
+		}
+		if a.Webhook.Topic != b.Webhook.Topic {
+			return a.Webhook.Topic < b.Webhook.Topic
+		}
+		return a.Webhook.Address < b.Webhook.Address
+	})
+
+	return plan, nil
+}
+
+// Apply makes the changes of a plan, in order. It stops at the first change
+// that fails.
+func (s *WebhookServiceOp) Apply(ctx context.Context, plan *WebhookPlan) error {
+	for _, c := range plan.Changes {
+		var err error
+		switch c.Action {
+		case WebhookActionCreate:
+			_, err = s.Create(ctx, c.Webhook)
+		case WebhookActionUpdate:
+			_, err = s.Update(ctx, c.Webhook)
+		case WebhookActionDelete:
+			err = s.Delete(ctx, c.Webhook.ID)
+		default:
+			err = fmt.Errorf("unknown webhook action %q", c.Action)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", c, err)
+		}
+	}
+	return nil
+}
+
+// Reconcile converges the webhook subscriptions of the shop to the desired
+// ones, see Plan, and returns the changes it made.
+func (s *WebhookServiceOp) Reconcile(ctx context.Context, desired []Webhook) (*WebhookPlan, error) {
+	plan, err := s.Plan(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	return plan, s.Apply(ctx, plan)
+}
+
+type webhookKey struct {
+	topic   string
+	address string
+}
+
+func webhookKeyOf(w Webhook) webhookKey {
+	return webhookKey{topic: w.Topic, address: w.Address}
+}
+
+func webhookFormat(w Webhook) string {
+	if w.Format == "" {
+		return "json"
+	}
+	return w.Format
+}
+
+// Reports whether two subscriptions with the same topic and address have the
+// same settings. The order of fields and metafield namespaces does not matter.
+func webhooksEqual(a, b Webhook) bool {
+	return webhookFormat(a) == webhookFormat(b) &&
+		sameStrings(a.Fields, b.Fields) &&
+		sameStrings(a.MetafieldNamespaces, b.MetafieldNamespaces)
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
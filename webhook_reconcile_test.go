@@ -0,0 +1,148 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+const existingWebhooks = `{"webhooks":[
+	{"id":1,"topic":"orders/create","address":"https://example.com/webhooks","format":"json","fields":[],"metafield_namespaces":[]},
+	{"id":2,"topic":"orders/paid","address":"https://example.com/webhooks","format":"json","fields":["id"],"metafield_namespaces":[]},
+	{"id":3,"topic":"orders/paid","address":"https://example.com/webhooks","format":"json","fields":["id"],"metafield_namespaces":[]},
+	{"id":4,"topic":"products/update","address":"https://old.example.com/webhooks","format":"json","fields":[],"metafield_namespaces":[]}
+]}`
+
+func desiredWebhooks() []Webhook {
+	return []Webhook{
+		{Topic: "orders/create", Address: "https://example.com/webhooks"},
+		{Topic: "orders/paid", Address: "https://example.com/webhooks", Fields: []string{"id", "financial_status"}},
+		{Topic: "products/update", Address: "https://example.com/webhooks", Format: "json"},
+	}
+}
+
+func TestWebhookPlan(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
+		httpmock.NewStringResponder(200, existingWebhooks))
+
+	plan, err := client.Webhook.Plan(context.Background(), desiredWebhooks())
+	if err != nil {
+		t.Fatalf("Webhook.Plan returned error: %v", err)
+	}
+
+	expected := "create products/update https://example.com/webhooks (json)\n" +
+		"update orders/paid https://example.com/webhooks (json) #2\n" +
+		"delete orders/paid https://example.com/webhooks (json) #3\n" +
+		"delete products/update https://old.example.com/webhooks (json) #4\n"
+	if plan.String() != expected {
+		t.Errorf("Webhook.Plan returned\n%s\nexpected\n%s", plan, expected)
+	}
+
+	update := plan.Changes[1]
+	if update.Current == nil || update.Current.ID != 2 || len(update.Current.Fields) != 1 {
+		t.Errorf("Webhook.Plan update.Current = %+v, expected webhook 2", update.Current)
+	}
+}
+
+func TestWebhookPlanNoChanges(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
+		httpmock.NewStringResponder(200, `{"webhooks":[{"id":1,"topic":"orders/create","address":"https://example.com/webhooks","format":"json","fields":["updated_at","id"]}]}`))
+
+	desired := []Webhook{{Topic: "orders/create", Address: "https://example.com/webhooks", Fields: []string{"id", "updated_at"}}}
+	plan, err := client.Webhook.Plan(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("Webhook.Plan returned error: %v", err)
+	}
+
+	if !plan.Empty() {
+		t.Errorf("Webhook.Plan returned\n%s\nexpected no changes", plan)
+	}
+}
+
+func TestWebhookPlanDuplicate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	desired := append(desiredWebhooks(), Webhook{Topic: "orders/create", Address: "https://example.com/webhooks"})
+	_, err := client.Webhook.Plan(context.Background(), desired)
+	if err == nil {
+		t.Errorf("Webhook.Plan expected error for duplicate webhooks")
+	}
+}
+
+func TestWebhookReconcile(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
+		httpmock.NewStringResponder(200, existingWebhooks))
+
+	var calls []string
+	record := func(status int, body string) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, req.Method+" "+req.URL.Path)
+			return httpmock.NewStringResponse(status, body), nil
+		}
+	}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
+		record(201, `{"webhook":{"id":5}}`))
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/2.json",
+		record(200, `{"webhook":{"id":2}}`))
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/3.json",
+		record(200, `{}`))
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/4.json",
+		record(200, `{}`))
+
+	plan, err := client.Webhook.Reconcile(context.Background(), desiredWebhooks())
+	if err != nil {
+		t.Fatalf("Webhook.Reconcile returned error: %v", err)
+	}
+
+	if len(plan.Changes) != 4 {
+		t.Errorf("Webhook.Reconcile returned %d changes, expected 4", len(plan.Changes))
+	}
+
+	expected := []string{
+		"POST /admin/api/2024-10/webhooks.json",
+		"PUT /admin/api/2024-10/webhooks/2.json",
+		"DELETE /admin/api/2024-10/webhooks/3.json",
+		"DELETE /admin/api/2024-10/webhooks/4.json",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Webhook.Reconcile made calls %v, expected %v", calls, expected)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Webhook.Reconcile call %d = %s, expected %s", i, calls[i], expected[i])
+		}
+	}
+}
+
+func TestWebhookApplyError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
+		httpmock.NewStringResponder(422, `{"errors":{"address":["for this topic has already been taken"]}}`))
+
+	plan := &WebhookPlan{Changes: []WebhookChange{
+		{Action: WebhookActionCreate, Webhook: Webhook{Topic: "orders/create", Address: "https://example.com/webhooks"}},
+		{Action: WebhookActionDelete, Webhook: Webhook{ID: 1, Topic: "orders/create", Address: "https://old.example.com/webhooks"}},
+	}}
+
+	err := client.Webhook.Apply(context.Background(), plan)
+
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("Webhook.Apply returned %v, expected a ValidationError", err)
+	}
+}
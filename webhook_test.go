@@ -2,6 +2,7 @@ package goshopify
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -21,9 +22,9 @@ func webhookTests(t *testing.T, webhook Webhook) {
 		t.Errorf("Webhook.Address returned %+v, expected %+v", webhook.Address, expectedStr)
 	}
 
-	expectedStr = "orders/create"
-	if webhook.Topic != expectedStr {
-		t.Errorf("Webhook.Topic returned %+v, expected %+v", webhook.Topic, expectedStr)
+	expectedTopic := string(TopicOrdersCreate)
+	if webhook.Topic != expectedTopic {
+		t.Errorf("Webhook.Topic returned %+v, expected %+v", webhook.Topic, expectedTopic)
 	}
 
 	expectedArr := []string{"id", "updated_at"}
@@ -41,7 +42,7 @@ func TestWebhookList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/webhooks.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
 		httpmock.NewBytesResponder(200, loadFixture("webhooks.json")))
 
 	webhooks, err := client.Webhook.List(context.Background(), nil)
@@ -61,7 +62,7 @@ func TestWebhookGet(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/webhooks/4759306.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/4759306.json",
 		httpmock.NewBytesResponder(200, loadFixture("webhook.json")))
 
 	webhook, err := client.Webhook.Get(context.Background(), 4759306, nil)
@@ -76,10 +77,10 @@ func TestWebhookCount(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/webhooks/count.json",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/count.json",
 		httpmock.NewStringResponder(200, `{"count": 7}`))
 
-	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/webhooks/count.json?topic=orders%2Fpaid",
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/count.json?topic=orders%2Fpaid",
 		httpmock.NewStringResponder(200, `{"count": 2}`))
 
 	cnt, err := client.Webhook.Count(context.Background(), nil)
@@ -108,7 +109,7 @@ func TestWebhookCreate(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/webhooks.json",
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
 		httpmock.NewBytesResponder(200, loadFixture("webhook.json")))
 
 	webhook := Webhook{
@@ -128,7 +129,7 @@ func TestWebhookUpdate(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/webhooks/4759306.json",
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/4759306.json",
 		httpmock.NewBytesResponder(200, loadFixture("webhook.json")))
 
 	webhook := Webhook{
@@ -149,7 +150,7 @@ func TestWebhookDelete(t *testing.T) {
 	setup()
 	defer teardown()
 
-	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/webhooks/4759306.json",
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/4759306.json",
 		httpmock.NewStringResponder(200, "{}"))
 
 	err := client.Webhook.Delete(context.Background(), 4759306)
@@ -157,3 +158,72 @@ func TestWebhookDelete(t *testing.T) {
 		t.Errorf("Webhook.Delete returned error: %v", err)
 	}
 }
+
+func TestWebhookCreateInvalid(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cases := []struct {
+		webhook  Webhook
+		expected error
+	}{
+		{Webhook{Topic: "order/create", Address: "http://example.com"}, ErrUnknownWebhookTopic},
+		{Webhook{Address: "http://example.com"}, ErrUnknownWebhookTopic},
+		{Webhook{Topic: string(TopicOrdersCreate), Address: "http://example.com", Format: "yaml"}, ErrInvalidWebhookFormat},
+	}
+
+	for _, c := range cases {
+		// No responder is registered, so a request would fail differently.
+		_, err := client.Webhook.Create(context.Background(), c.webhook)
+		if !errors.Is(err, c.expected) {
+			t.Errorf("Webhook.Create(%+v) returned %v, expected %v", c.webhook, err, c.expected)
+		}
+	}
+
+	_, err := client.Webhook.Update(context.Background(), Webhook{ID: 1, Topic: "order/create"})
+	if !errors.Is(err, ErrUnknownWebhookTopic) {
+		t.Errorf("Webhook.Update returned %v, expected %v", err, ErrUnknownWebhookTopic)
+	}
+}
+
+func TestWebhookCreateRegisteredTopic(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks.json",
+		httpmock.NewBytesResponder(200, loadFixture("webhook.json")))
+
+	topic := "inventory_shipments/create"
+	webhook := Webhook{Topic: topic, Address: "http://example.com"}
+
+	_, err := client.Webhook.Create(context.Background(), webhook)
+	if !errors.Is(err, ErrUnknownWebhookTopic) {
+		t.Errorf("Webhook.Create returned %v, expected %v", err, ErrUnknownWebhookTopic)
+	}
+
+	// Topics that this package doesn't list yet can be registered.
+	RegisterWebhookTopic(WebhookTopic(topic), map[string]interface{}{})
+	defer func() {
+		webhookTopics.Lock()
+		delete(webhookTopics.payloads, WebhookTopic(topic))
+		webhookTopics.Unlock()
+	}()
+
+	_, err = client.Webhook.Create(context.Background(), webhook)
+	if err != nil {
+		t.Errorf("Webhook.Create returned error: %v", err)
+	}
+}
+
+func TestWebhookUpdateWithoutTopic(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/api/2024-10/webhooks/4759306.json",
+		httpmock.NewBytesResponder(200, loadFixture("webhook.json")))
+
+	_, err := client.Webhook.Update(context.Background(), Webhook{ID: 4759306, Address: "http://example.com", Format: "xml"})
+	if err != nil {
+		t.Errorf("Webhook.Update returned error: %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+package goshopify
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// WebhookTopic is the event that a webhook subscription is for.
+type WebhookTopic string
+
+// Webhook topics, see https://shopify.dev/docs/api/admin-rest/latest/resources/webhook#event-topics
+const (
+	TopicAppUninstalled                          WebhookTopic = "app/uninstalled"
+	TopicAppSubscriptionsUpdate                  WebhookTopic = "app_subscriptions/update"
+	TopicAppSubscriptionsApproachingCappedAmount WebhookTopic = "app_subscriptions/approaching_capped_amount"
+	TopicAppPurchasesOneTimeUpdate               WebhookTopic = "app_purchases_one_time/update"
+
+	TopicBulkOperationsFinish WebhookTopic = "bulk_operations/finish"
+
+	TopicCartsCreate WebhookTopic = "carts/create"
+	TopicCartsUpdate WebhookTopic = "carts/update"
+
+	TopicCheckoutsCreate WebhookTopic = "checkouts/create"
+	TopicCheckoutsUpdate WebhookTopic = "checkouts/update"
+	TopicCheckoutsDelete WebhookTopic = "checkouts/delete"
+
+	TopicCollectionListingsAdd    WebhookTopic = "collection_listings/add"
+	TopicCollectionListingsUpdate WebhookTopic = "collection_listings/update"
+	TopicCollectionListingsRemove WebhookTopic = "collection_listings/remove"
+
+	TopicCollectionsCreate WebhookTopic = "collections/create"
+	TopicCollectionsUpdate WebhookTopic = "collections/update"
+	TopicCollectionsDelete WebhookTopic = "collections/delete"
+
+	TopicCustomerPaymentMethodsCreate WebhookTopic = "customer_payment_methods/create"
+	TopicCustomerPaymentMethodsUpdate WebhookTopic = "customer_payment_methods/update"
+	TopicCustomerPaymentMethodsRevoke WebhookTopic = "customer_payment_methods/revoke"
+
+	TopicCustomersCreate                      WebhookTopic = "customers/create"
+	TopicCustomersUpdate                      WebhookTopic = "customers/update"
+	TopicCustomersDelete                      WebhookTopic = "customers/delete"
+	TopicCustomersEnable                      WebhookTopic = "customers/enable"
+	TopicCustomersDisable                     WebhookTopic = "customers/disable"
+	TopicCustomersMerge                       WebhookTopic = "customers/merge"
+	TopicCustomersDataRequest                 WebhookTopic = "customers/data_request"
+	TopicCustomersRedact                      WebhookTopic = "customers/redact"
+	TopicCustomersEmailMarketingConsentUpdate WebhookTopic = "customers_email_marketing_consent/update"
+	TopicCustomersMarketingConsentUpdate      WebhookTopic = "customers_marketing_consent/update"
+
+	TopicDisputesCreate WebhookTopic = "disputes/create"
+	TopicDisputesUpdate WebhookTopic = "disputes/update"
+
+	TopicDomainsCreate  WebhookTopic = "domains/create"
+	TopicDomainsUpdate  WebhookTopic = "domains/update"
+	TopicDomainsDestroy WebhookTopic = "domains/destroy"
+
+	TopicDraftOrdersCreate WebhookTopic = "draft_orders/create"
+	TopicDraftOrdersUpdate WebhookTopic = "draft_orders/update"
+	TopicDraftOrdersDelete WebhookTopic = "draft_orders/delete"
+
+	TopicFulfillmentEventsCreate WebhookTopic = "fulfillment_events/create"
+	TopicFulfillmentEventsDelete WebhookTopic = "fulfillment_events/delete"
+
+	TopicFulfillmentOrdersCancellationRequestAccepted        WebhookTopic = "fulfillment_orders/cancellation_request_accepted"
+	TopicFulfillmentOrdersCancellationRequestRejected        WebhookTopic = "fulfillment_orders/cancellation_request_rejected"
+	TopicFulfillmentOrdersCancellationRequestSubmitted       WebhookTopic = "fulfillment_orders/cancellation_request_submitted"
+	TopicFulfillmentOrdersCancelled                          WebhookTopic = "fulfillment_orders/cancelled"
+	TopicFulfillmentOrdersFulfillmentRequestAccepted         WebhookTopic = "fulfillment_orders/fulfillment_request_accepted"
+	TopicFulfillmentOrdersFulfillmentRequestRejected         WebhookTopic = "fulfillment_orders/fulfillment_request_rejected"
+	TopicFulfillmentOrdersFulfillmentRequestSubmitted        WebhookTopic = "fulfillment_orders/fulfillment_request_submitted"
+	TopicFulfillmentOrdersFulfillmentServiceFailedToComplete WebhookTopic = "fulfillment_orders/fulfillment_service_failed_to_complete"
+	TopicFulfillmentOrdersHoldReleased                       WebhookTopic = "fulfillment_orders/hold_released"
+	TopicFulfillmentOrdersLineItemsPreparedForLocalDelivery  WebhookTopic = "fulfillment_orders/line_items_prepared_for_local_delivery"
+	TopicFulfillmentOrdersLineItemsPreparedForPickup         WebhookTopic = "fulfillment_orders/line_items_prepared_for_pickup"
+	TopicFulfillmentOrdersMoved                              WebhookTopic = "fulfillment_orders/moved"
+	TopicFulfillmentOrdersOrderRoutingComplete               WebhookTopic = "fulfillment_orders/order_routing_complete"
+	TopicFulfillmentOrdersPlacedOnHold                       WebhookTopic = "fulfillment_orders/placed_on_hold"
+	TopicFulfillmentOrdersRescheduled                        WebhookTopic = "fulfillment_orders/rescheduled"
+	TopicFulfillmentOrdersScheduledFulfillmentOrderReady     WebhookTopic = "fulfillment_orders/scheduled_fulfillment_order_ready"
+
+	TopicFulfillmentsCreate WebhookTopic = "fulfillments/create"
+	TopicFulfillmentsUpdate WebhookTopic = "fulfillments/update"
+
+	TopicInventoryItemsCreate WebhookTopic = "inventory_items/create"
+	TopicInventoryItemsUpdate WebhookTopic = "inventory_items/update"
+	TopicInventoryItemsDelete WebhookTopic = "inventory_items/delete"
+
+	TopicInventoryLevelsConnect    WebhookTopic = "inventory_levels/connect"
+	TopicInventoryLevelsUpdate     WebhookTopic = "inventory_levels/update"
+	TopicInventoryLevelsDisconnect WebhookTopic = "inventory_levels/disconnect"
+
+	TopicLocalesCreate WebhookTopic = "locales/create"
+	TopicLocalesUpdate WebhookTopic = "locales/update"
+
+	TopicLocationsCreate     WebhookTopic = "locations/create"
+	TopicLocationsUpdate     WebhookTopic = "locations/update"
+	TopicLocationsDelete     WebhookTopic = "locations/delete"
+	TopicLocationsActivate   WebhookTopic = "locations/activate"
+	TopicLocationsDeactivate WebhookTopic = "locations/deactivate"
+
+	TopicMarketsCreate WebhookTopic = "markets/create"
+	TopicMarketsUpdate WebhookTopic = "markets/update"
+	TopicMarketsDelete WebhookTopic = "markets/delete"
+
+	TopicOrderTransactionsCreate WebhookTopic = "order_transactions/create"
+
+	TopicOrdersCreate                WebhookTopic = "orders/create"
+	TopicOrdersUpdated               WebhookTopic = "orders/updated"
+	TopicOrdersDelete                WebhookTopic = "orders/delete"
+	TopicOrdersPaid                  WebhookTopic = "orders/paid"
+	TopicOrdersCancelled             WebhookTopic = "orders/cancelled"
+	TopicOrdersFulfilled             WebhookTopic = "orders/fulfilled"
+	TopicOrdersPartiallyFulfilled    WebhookTopic = "orders/partially_fulfilled"
+	TopicOrdersEdited                WebhookTopic = "orders/edited"
+	TopicOrdersRiskAssessmentChanged WebhookTopic = "orders/risk_assessment_changed"
+
+	TopicPaymentTermsCreate WebhookTopic = "payment_terms/create"
+	TopicPaymentTermsUpdate WebhookTopic = "payment_terms/update"
+	TopicPaymentTermsDelete WebhookTopic = "payment_terms/delete"
+
+	TopicProductListingsAdd    WebhookTopic = "product_listings/add"
+	TopicProductListingsUpdate WebhookTopic = "product_listings/update"
+	TopicProductListingsRemove WebhookTopic = "product_listings/remove"
+
+	TopicProductsCreate WebhookTopic = "products/create"
+	TopicProductsUpdate WebhookTopic = "products/update"
+	TopicProductsDelete WebhookTopic = "products/delete"
+
+	TopicProfilesCreate WebhookTopic = "profiles/create"
+	TopicProfilesUpdate WebhookTopic = "profiles/update"
+	TopicProfilesDelete WebhookTopic = "profiles/delete"
+
+	TopicRefundsCreate WebhookTopic = "refunds/create"
+
+	TopicSellingPlanGroupsCreate WebhookTopic = "selling_plan_groups/create"
+	TopicSellingPlanGroupsUpdate WebhookTopic = "selling_plan_groups/update"
+	TopicSellingPlanGroupsDelete WebhookTopic = "selling_plan_groups/delete"
+
+	TopicShopUpdate WebhookTopic = "shop/update"
+	TopicShopRedact WebhookTopic = "shop/redact"
+
+	TopicSubscriptionBillingAttemptsSuccess    WebhookTopic = "subscription_billing_attempts/success"
+	TopicSubscriptionBillingAttemptsFailure    WebhookTopic = "subscription_billing_attempts/failure"
+	TopicSubscriptionBillingAttemptsChallenged WebhookTopic = "subscription_billing_attempts/challenged"
+
+	TopicSubscriptionContractsCreate WebhookTopic = "subscription_contracts/create"
+	TopicSubscriptionContractsUpdate WebhookTopic = "subscription_contracts/update"
+
+	TopicTenderTransactionsCreate WebhookTopic = "tender_transactions/create"
+
+	TopicThemesCreate  WebhookTopic = "themes/create"
+	TopicThemesPublish WebhookTopic = "themes/publish"
+	TopicThemesUpdate  WebhookTopic = "themes/update"
+	TopicThemesDelete  WebhookTopic = "themes/delete"
+)
+
+// Errors returned by WebhookService.Create and Update for invalid webhooks,
+// before making a request.
+var (
+	ErrUnknownWebhookTopic  = errors.New("unknown webhook topic")
+	ErrInvalidWebhookFormat = errors.New("invalid webhook format")
+)
+
+// The payload types of the topics. Topics without a model in this package
+// have a generic payload.
+var webhookTopics = struct {
+	sync.RWMutex
+	payloads map[WebhookTopic]reflect.Type
+}{
+	payloads: map[WebhookTopic]reflect.Type{
+		TopicAppUninstalled:                          reflect.TypeOf(Shop{}),
+		TopicAppSubscriptionsUpdate:                  genericPayload,
+		TopicAppSubscriptionsApproachingCappedAmount: genericPayload,
+		TopicAppPurchasesOneTimeUpdate:               genericPayload,
+
+		TopicBulkOperationsFinish: genericPayload,
+
+		TopicCartsCreate: genericPayload,
+		TopicCartsUpdate: genericPayload,
+
+		TopicCheckoutsCreate: genericPayload,
+		TopicCheckoutsUpdate: genericPayload,
+		TopicCheckoutsDelete: genericPayload,
+
+		TopicCollectionListingsAdd:    genericPayload,
+		TopicCollectionListingsUpdate: genericPayload,
+		TopicCollectionListingsRemove: genericPayload,
+
+		TopicCollectionsCreate: genericPayload,
+		TopicCollectionsUpdate: genericPayload,
+		TopicCollectionsDelete: genericPayload,
+
+		TopicCustomerPaymentMethodsCreate: genericPayload,
+		TopicCustomerPaymentMethodsUpdate: genericPayload,
+		TopicCustomerPaymentMethodsRevoke: genericPayload,
+
+		TopicCustomersCreate:                      reflect.TypeOf(Customer{}),
+		TopicCustomersUpdate:                      reflect.TypeOf(Customer{}),
+		TopicCustomersDelete:                      reflect.TypeOf(Customer{}),
+		TopicCustomersEnable:                      reflect.TypeOf(Customer{}),
+		TopicCustomersDisable:                     reflect.TypeOf(Customer{}),
+		TopicCustomersMerge:                       genericPayload,
+		TopicCustomersDataRequest:                 genericPayload,
+		TopicCustomersRedact:                      genericPayload,
+		TopicCustomersEmailMarketingConsentUpdate: genericPayload,
+		TopicCustomersMarketingConsentUpdate:      genericPayload,
+
+		TopicDisputesCreate: genericPayload,
+		TopicDisputesUpdate: genericPayload,
+
+		TopicDomainsCreate:  genericPayload,
+		TopicDomainsUpdate:  genericPayload,
+		TopicDomainsDestroy: genericPayload,
+
+		TopicDraftOrdersCreate: genericPayload,
+		TopicDraftOrdersUpdate: genericPayload,
+		TopicDraftOrdersDelete: genericPayload,
+
+		TopicFulfillmentEventsCreate: genericPayload,
+		TopicFulfillmentEventsDelete: genericPayload,
+
+		TopicFulfillmentOrdersCancellationRequestAccepted:        genericPayload,
+		TopicFulfillmentOrdersCancellationRequestRejected:        genericPayload,
+		TopicFulfillmentOrdersCancellationRequestSubmitted:       genericPayload,
+		TopicFulfillmentOrdersCancelled:                          genericPayload,
+		TopicFulfillmentOrdersFulfillmentRequestAccepted:         genericPayload,
+		TopicFulfillmentOrdersFulfillmentRequestRejected:         genericPayload,
+		TopicFulfillmentOrdersFulfillmentRequestSubmitted:        genericPayload,
+		TopicFulfillmentOrdersFulfillmentServiceFailedToComplete: genericPayload,
+		TopicFulfillmentOrdersHoldReleased:                       genericPayload,
+		TopicFulfillmentOrdersLineItemsPreparedForLocalDelivery:  genericPayload,
+		TopicFulfillmentOrdersLineItemsPreparedForPickup:         genericPayload,
+		TopicFulfillmentOrdersMoved:                              genericPayload,
+		TopicFulfillmentOrdersOrderRoutingComplete:               genericPayload,
+		TopicFulfillmentOrdersPlacedOnHold:                       genericPayload,
+		TopicFulfillmentOrdersRescheduled:                        genericPayload,
+		TopicFulfillmentOrdersScheduledFulfillmentOrderReady:     genericPayload,
+
+		TopicFulfillmentsCreate: genericPayload,
+		TopicFulfillmentsUpdate: genericPayload,
+
+		TopicInventoryItemsCreate: genericPayload,
+		TopicInventoryItemsUpdate: genericPayload,
+		TopicInventoryItemsDelete: genericPayload,
+
+		TopicInventoryLevelsConnect:    genericPayload,
+		TopicInventoryLevelsUpdate:     genericPayload,
+		TopicInventoryLevelsDisconnect: genericPayload,
+
+		TopicLocalesCreate: genericPayload,
+		TopicLocalesUpdate: genericPayload,
+
+		TopicLocationsCreate:     genericPayload,
+		TopicLocationsUpdate:     genericPayload,
+		TopicLocationsDelete:     genericPayload,
+		TopicLocationsActivate:   genericPayload,
+		TopicLocationsDeactivate: genericPayload,
+
+		TopicMarketsCreate: genericPayload,
+		TopicMarketsUpdate: genericPayload,
+		TopicMarketsDelete: genericPayload,
+
+		TopicOrderTransactionsCreate: reflect.TypeOf(Transaction{}),
+
+		TopicOrdersCreate:                reflect.TypeOf(Order{}),
+		TopicOrdersUpdated:               reflect.TypeOf(Order{}),
+		TopicOrdersDelete:                reflect.TypeOf(Order{}),
+		TopicOrdersPaid:                  reflect.TypeOf(Order{}),
+		TopicOrdersCancelled:             reflect.TypeOf(Order{}),
+		TopicOrdersFulfilled:             reflect.TypeOf(Order{}),
+		TopicOrdersPartiallyFulfilled:    reflect.TypeOf(Order{}),
+		TopicOrdersEdited:                genericPayload,
+		TopicOrdersRiskAssessmentChanged: genericPayload,
+
+		TopicPaymentTermsCreate: genericPayload,
+		TopicPaymentTermsUpdate: genericPayload,
+		TopicPaymentTermsDelete: genericPayload,
+
+		TopicProductListingsAdd:    genericPayload,
+		TopicProductListingsUpdate: genericPayload,
+		TopicProductListingsRemove: genericPayload,
+
+		TopicProductsCreate: reflect.TypeOf(Product{}),
+		TopicProductsUpdate: reflect.TypeOf(Product{}),
+		TopicProductsDelete: reflect.TypeOf(Product{}),
+
+		TopicProfilesCreate: genericPayload,
+		TopicProfilesUpdate: genericPayload,
+		TopicProfilesDelete: genericPayload,
+
+		TopicRefundsCreate: genericPayload,
+
+		TopicSellingPlanGroupsCreate: genericPayload,
+		TopicSellingPlanGroupsUpdate: genericPayload,
+		TopicSellingPlanGroupsDelete: genericPayload,
+
+		TopicShopUpdate: reflect.TypeOf(Shop{}),
+		TopicShopRedact: genericPayload,
+
+		TopicSubscriptionBillingAttemptsSuccess:    genericPayload,
+		TopicSubscriptionBillingAttemptsFailure:    genericPayload,
+		TopicSubscriptionBillingAttemptsChallenged: genericPayload,
+
+		TopicSubscriptionContractsCreate: genericPayload,
+		TopicSubscriptionContractsUpdate: genericPayload,
+
+		TopicTenderTransactionsCreate: genericPayload,
+
+		TopicThemesCreate:  genericPayload,
+		TopicThemesPublish: genericPayload,
+		TopicThemesUpdate:  genericPayload,
+		TopicThemesDelete:  genericPayload,
+	},
+}
+
+var genericPayload = reflect.TypeOf(map[string]interface{}{})
+
+// RegisterWebhookTopic adds a topic to the known topics, or replaces the
+// payload type of a known one, e.g. to use a topic that was added to Shopify
+// after this package or to decode a payload into your own model. The payload
+// is an example value of the payload type, e.g. MyOrder{}.
+func RegisterWebhookTopic(topic WebhookTopic, payload interface{}) {
+	webhookTopics.Lock()
+	defer webhookTopics.Unlock()
+	webhookTopics.payloads[topic] = reflect.TypeOf(payload)
+}
+
+// WebhookPayloadType returns the Go type that the payload of a topic decodes
+// into, e.g. Order for orders/create. It returns false for unknown topics.
+func WebhookPayloadType(topic WebhookTopic) (reflect.Type, bool) {
+	webhookTopics.RLock()
+	defer webhookTopics.RUnlock()
+	t, ok := webhookTopics.payloads[topic]
+	return t, ok
+}
+
+// NewWebhookPayload returns a pointer to a new value of the payload type of a
+// topic, to decode a payload into. It returns nil for unknown topics.
+func NewWebhookPayload(topic WebhookTopic) interface{} {
+	t, ok := WebhookPayloadType(topic)
+	if !ok {
+		return nil
+	}
+	return reflect.New(t).Interface()
+}
+
+// Known reports whether the topic is known to this package or registered with
+// RegisterWebhookTopic.
+func (t WebhookTopic) Known() bool {
+	_, ok := WebhookPayloadType(t)
+	return ok
+}
+
+// Validates the topic and format of a webhook before it is sent to Shopify.
+// An empty topic is only valid for updates, since the topic of a webhook
+// cannot be changed. Topics that this package doesn't list yet can be added
+// with RegisterWebhookTopic.
+func validateWebhook(webhook Webhook, update bool) error {
+	topic := WebhookTopic(webhook.Topic)
+	if (topic != "" || !update) && !topic.Known() {
+		return fmt.Errorf("%w: %q", ErrUnknownWebhookTopic, webhook.Topic)
+	}
+	switch webhook.Format {
+	case "", "json", "xml":
+	default:
+		return fmt.Errorf("%w: %q, expected json or xml", ErrInvalidWebhookFormat, webhook.Format)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package goshopify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebhookPayloadType(t *testing.T) {
+	cases := []struct {
+		topic    WebhookTopic
+		expected reflect.Type
+	}{
+		{TopicOrdersCreate, reflect.TypeOf(Order{})},
+		{TopicProductsUpdate, reflect.TypeOf(Product{})},
+		{TopicCustomersCreate, reflect.TypeOf(Customer{})},
+		{TopicShopUpdate, reflect.TypeOf(Shop{})},
+		{TopicCustomersRedact, reflect.TypeOf(map[string]interface{}{})},
+		{TopicInventoryLevelsUpdate, reflect.TypeOf(map[string]interface{}{})},
+		{TopicBulkOperationsFinish, reflect.TypeOf(map[string]interface{}{})},
+	}
+
+	for _, c := range cases {
+		actual, ok := WebhookPayloadType(c.topic)
+		if !ok || actual != c.expected {
+			t.Errorf("WebhookPayloadType(%s) = %v, %v, expected %v", c.topic, actual, ok, c.expected)
+		}
+	}
+
+	if _, ok := WebhookPayloadType("order/create"); ok {
+		t.Errorf("WebhookPayloadType(order/create): expected an unknown topic")
+	}
+
+	if _, ok := NewWebhookPayload(TopicOrdersPaid).(*Order); !ok {
+		t.Errorf("NewWebhookPayload(%s) = %T, expected *Order", TopicOrdersPaid, NewWebhookPayload(TopicOrdersPaid))
+	}
+	if payload := NewWebhookPayload("order/create"); payload != nil {
+		t.Errorf("NewWebhookPayload(order/create) = %v, expected nil", payload)
+	}
+}
+
+func TestRegisterWebhookTopic(t *testing.T) {
+	type inventoryShipment struct {
+		ID int `json:"id"`
+	}
+
+	topic := WebhookTopic("inventory_shipments/create")
+	if topic.Known() {
+		t.Fatalf("WebhookTopic(%s).Known(): expected false before registration", topic)
+	}
+
+	RegisterWebhookTopic(topic, inventoryShipment{})
+	defer func() {
+		webhookTopics.Lock()
+		delete(webhookTopics.payloads, topic)
+		webhookTopics.Unlock()
+	}()
+
+	if !topic.Known() {
+		t.Errorf("WebhookTopic(%s).Known(): expected true after registration", topic)
+	}
+	if _, ok := NewWebhookPayload(topic).(*inventoryShipment); !ok {
+		t.Errorf("NewWebhookPayload(%s) = %T, expected *inventoryShipment", topic, NewWebhookPayload(topic))
+	}
+}